@@ -0,0 +1,87 @@
+// Command gravity-keys administers the bbolt-backed access-key store used
+// by the OTLP ingest endpoint's auth middleware.
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/youware/gravity/internal/ingest/auth"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	dbPath := getEnv("AUTH_DB_PATH", "gravity-keys.db")
+
+	store, err := auth.NewBoltKeyStore(dbPath)
+	if err != nil {
+		log.Fatalf("failed to open key store at %s: %v", dbPath, err)
+	}
+	defer store.Close()
+
+	switch os.Args[1] {
+	case "generate":
+		generate(store, os.Args[2:])
+	case "revoke":
+		revoke(store, os.Args[2:])
+	default:
+		usage()
+		os.Exit(1)
+	}
+}
+
+func generate(store *auth.BoltKeyStore, args []string) {
+	if len(args) < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: gravity-keys generate <org_id> [requests_per_second]")
+		os.Exit(1)
+	}
+
+	var limit auth.RateLimit
+	if len(args) > 1 {
+		rps, err := strconv.Atoi(args[1])
+		if err != nil {
+			log.Fatalf("invalid requests_per_second %q: %v", args[1], err)
+		}
+		limit.RequestsPerSecond = rps
+	}
+
+	key, err := store.Generate(args[0], limit)
+	if err != nil {
+		log.Fatalf("failed to generate key: %v", err)
+	}
+
+	fmt.Printf("org_id:     %s\n", key.OrgID)
+	fmt.Printf("access_key: %s\n", key.AccessKey)
+	fmt.Printf("secret:     %s\n", key.Secret)
+	fmt.Printf("Authorization: Bearer %s:%s\n", key.AccessKey, key.Secret)
+}
+
+func revoke(store *auth.BoltKeyStore, args []string) {
+	if len(args) < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: gravity-keys revoke <access_key>")
+		os.Exit(1)
+	}
+
+	if err := store.Revoke(args[0]); err != nil {
+		log.Fatalf("failed to revoke key: %v", err)
+	}
+
+	fmt.Printf("revoked access key %s\n", args[0])
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: gravity-keys <generate|revoke> ...")
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,77 @@
+// Command gravity-replay re-issues a request captured by the ingest
+// reproducer against a target endpoint, so a production 400/500 can be
+// reproduced deterministically instead of debugged from logs alone.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/youware/gravity/internal/ingest/reproducer"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	requestID := os.Args[1]
+	dir := getEnv("REPRODUCER_DIR", "gravity-captures")
+	target := getEnv("REPLAY_TARGET", "http://localhost:8080")
+
+	metaFile, err := os.Open(fmt.Sprintf("%s/%s.json", dir, requestID))
+	if err != nil {
+		log.Fatalf("failed to open artifact metadata: %v", err)
+	}
+	defer metaFile.Close()
+
+	bodyFile, err := os.Open(fmt.Sprintf("%s/%s.bin", dir, requestID))
+	if err != nil {
+		log.Fatalf("failed to open artifact body: %v", err)
+	}
+	defer bodyFile.Close()
+
+	artifact, body, err := reproducer.Load(metaFile, bodyFile)
+	if err != nil {
+		log.Fatalf("failed to load artifact: %v", err)
+	}
+
+	url := target + artifact.Path
+	req, err := http.NewRequest(artifact.Method, url, bytes.NewReader(body))
+	if err != nil {
+		log.Fatalf("failed to build replay request: %v", err)
+	}
+	for k, vs := range artifact.Headers {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+
+	fmt.Printf("replaying %s %s (org_id=%s, reason=%s, captured_at=%s)\n",
+		artifact.Method, url, artifact.OrgID, artifact.Reason, artifact.CapturedAt)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Fatalf("replay request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	fmt.Printf("response status: %s\n", resp.Status)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: gravity-replay <request_id>")
+	fmt.Fprintln(os.Stderr, "  REPRODUCER_DIR  directory captured artifacts were written to (default gravity-captures)")
+	fmt.Fprintln(os.Stderr, "  REPLAY_TARGET   base URL to replay the request against (default http://localhost:8080)")
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/youware/gravity/internal/ingest/http"
 	"github.com/youware/gravity/internal/shared/config"
+	"github.com/youware/gravity/internal/shared/tracing"
 )
 
 func main() {
@@ -34,6 +35,20 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Self-instrument with OpenTelemetry traces/metrics
+	tracingShutdown, err := tracing.Init(ctx, "gravity-ingest-http")
+	if err != nil {
+		logger.Error("failed to initialize tracing", "error", err)
+		os.Exit(1)
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := tracingShutdown(shutdownCtx); err != nil {
+			logger.Error("error shutting down tracing", "error", err)
+		}
+	}()
+
 	// Create HTTP server
 	srv, err := http.NewServer(cfg)
 	if err != nil {
@@ -50,6 +65,24 @@ func main() {
 		}
 	}()
 
+	// SIGHUP reloads the span filter's CEL expressions without a restart
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	go func() {
+		for range hupChan {
+			reloadCfg, err := config.Load()
+			if err != nil {
+				logger.Error("failed to reload config on SIGHUP", "error", err)
+				continue
+			}
+			if err := srv.ReloadFilter(reloadCfg.Filter.Expressions); err != nil {
+				logger.Error("failed to reload filter expressions on SIGHUP", "error", err)
+				continue
+			}
+			logger.Info("reloaded filter expressions on SIGHUP", "count", len(reloadCfg.Filter.Expressions))
+		}
+	}()
+
 	// Wait for interrupt signal or server error
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
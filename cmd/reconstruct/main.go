@@ -1,149 +1,137 @@
 package main
 
 import (
-	"bytes"
-	"compress/gzip"
 	"context"
-	"encoding/json"
+	"flag"
 	"fmt"
-	"io"
 	"log"
 	"os"
+	"strconv"
 	"strings"
 
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/youware/gravity/internal/blobcodec"
+	"github.com/youware/gravity/internal/reconstruct"
+	"github.com/youware/gravity/internal/storage"
 )
 
-// ChunkIndex represents the index mapping trace_id to content hashes
-type ChunkIndex struct {
-	TraceID string   `json:"trace_id"`
-	SpanID  string   `json:"span_id"`
-	Hashes  []string `json:"hashes"`
-}
-
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Fprintf(os.Stderr, "Usage: %s <trace_id>\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "\nExample:\n")
-		fmt.Fprintf(os.Stderr, "  %s 44e0c73c00b2914b0b08945fd2665935\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "\nEnvironment variables:\n")
-		fmt.Fprintf(os.Stderr, "  S3_BUCKET (default: traces)\n")
-		fmt.Fprintf(os.Stderr, "  S3_REGION (default: us-east-1)\n")
-		fmt.Fprintf(os.Stderr, "  AWS_ENDPOINT_URL (for MinIO)\n")
+	rangeFlag := flag.String("range", "", "only reconstruct bytes start:end of the logical content (e.g. --range 0:1024)")
+	orgFlag := flag.String("org", getEnv("ORG_ID", ""), "org ID the trace belongs to (required if its chunks were compressed against a trained dictionary, or if ORG_SCOPED_KEYS is set)")
+	orgScopedFlag := flag.Bool("org-scoped-keys", getBoolEnv("ORG_SCOPED_KEYS", false), "whether the deployment was run with cfg.OrgScopedKeys, nesting index/blob/pack keys under orgs/{org}/")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		usage()
 		os.Exit(1)
 	}
-
-	traceID := os.Args[1]
-
-	// Get config from environment
-	bucket := getEnv("S3_BUCKET", "traces")
-	region := getEnv("S3_REGION", "us-east-1")
+	traceID := flag.Arg(0)
+	provider := getEnv("STORAGE_PROVIDER", "s3")
 
 	log.Printf("Reconstructing trace: %s", traceID)
-	log.Printf("Using S3 bucket: %s (region: %s)", bucket, region)
-
-	// Load AWS config
-	awsCfg, err := config.LoadDefaultConfig(context.Background(),
-		config.WithRegion(region),
-	)
-	if err != nil {
-		log.Fatalf("Failed to load AWS config: %v", err)
-	}
-
-	// Create S3 client with path-style addressing for MinIO compatibility
-	s3Client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
-		o.UsePathStyle = true
-	})
+	log.Printf("Using storage provider: %s", provider)
 
-	// Step 1: Download and parse index
-	log.Printf("Step 1: Fetching index for trace %s...", traceID)
-	index, err := downloadIndex(context.Background(), s3Client, bucket, traceID)
+	bucket, err := storage.New(context.Background(), storageConfig(provider))
 	if err != nil {
-		log.Fatalf("Failed to download index: %v", err)
+		log.Fatalf("Failed to create storage backend: %v", err)
 	}
 
-	log.Printf("Found index with %d chunks", len(index.Hashes))
-
-	// Step 2: Download and decompress each blob
-	log.Printf("Step 2: Downloading and decompressing %d blobs...", len(index.Hashes))
-	var reconstructed strings.Builder
-	for i, hash := range index.Hashes {
-		log.Printf("  [%d/%d] Fetching blob %s...", i+1, len(index.Hashes), hash[:12])
-
-		content, err := downloadAndDecompressBlob(context.Background(), s3Client, bucket, hash)
+	client := reconstruct.NewClient(bucket, getEnv("INDEXES_PATH", "indexes/"), getEnv("BLOBS_PATH", "blobs/")).
+		WithCodec(blobcodec.FromConfig(getEnv("COMPRESSION", "zstd"))).
+		WithOrg(*orgFlag).
+		WithOrgScopedKeys(*orgScopedFlag).
+		WithDictionary(getEnv("DICTS_PATH", "dicts/")).
+		WithPacks(getEnv("PACKS_PATH", "packs/"))
+	ctx := context.Background()
+
+	var content string
+	if *rangeFlag != "" {
+		off, n, err := parseRange(*rangeFlag)
 		if err != nil {
-			log.Fatalf("Failed to download blob %s: %v", hash, err)
+			log.Fatalf("Invalid --range: %v", err)
 		}
-
-		// Append with newline separator (since we chunked by newlines)
-		if i > 0 {
-			reconstructed.WriteString("\n")
+		log.Printf("Fetching byte range [%d, %d)...", off, off+n)
+		content, err = client.ReadRange(ctx, traceID, off, n)
+		if err != nil {
+			log.Fatalf("Failed to reconstruct range: %v", err)
+		}
+	} else {
+		content, err = client.ReadAll(ctx, traceID)
+		if err != nil {
+			log.Fatalf("Failed to reconstruct trace: %v", err)
 		}
-		reconstructed.WriteString(content)
 	}
 
-	// Step 3: Output reconstructed content
 	fmt.Println("\n" + strings.Repeat("=", 80))
-	fmt.Printf("Reconstructed content for trace %s (span %s):\n", index.TraceID, index.SpanID)
+	fmt.Printf("Reconstructed content for trace %s:\n", traceID)
 	fmt.Println(strings.Repeat("=", 80))
-	fmt.Println(reconstructed.String())
+	fmt.Println(content)
 	fmt.Println(strings.Repeat("=", 80))
 
-	log.Printf("✓ Successfully reconstructed %d bytes from %d chunks", reconstructed.Len(), len(index.Hashes))
+	log.Printf("✓ Successfully reconstructed %d bytes", len(content))
 }
 
-func downloadIndex(ctx context.Context, s3Client *s3.Client, bucket, traceID string) (*ChunkIndex, error) {
-	key := fmt.Sprintf("indexes/%s.json", traceID)
-
-	result, err := s3Client.GetObject(ctx, &s3.GetObjectInput{
-		Bucket: &bucket,
-		Key:    &key,
-	})
+// parseRange parses a "start:end" byte range into (offset, length).
+func parseRange(s string) (off, n int64, err error) {
+	parts := strings.SplitN(s, ":", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("expected start:end, got %q", s)
+	}
+	start, err := strconv.ParseInt(parts[0], 10, 64)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get index from S3: %w", err)
+		return 0, 0, fmt.Errorf("invalid start %q: %w", parts[0], err)
 	}
-	defer result.Body.Close()
-
-	data, err := io.ReadAll(result.Body)
+	end, err := strconv.ParseInt(parts[1], 10, 64)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read index body: %w", err)
+		return 0, 0, fmt.Errorf("invalid end %q: %w", parts[1], err)
 	}
-
-	var index ChunkIndex
-	if err := json.Unmarshal(data, &index); err != nil {
-		return nil, fmt.Errorf("failed to parse index JSON: %w", err)
+	if end < start {
+		return 0, 0, fmt.Errorf("end %d is before start %d", end, start)
 	}
-
-	return &index, nil
+	return start, end - start, nil
 }
 
-func downloadAndDecompressBlob(ctx context.Context, s3Client *s3.Client, bucket, hash string) (string, error) {
-	// Construct blob key: blobs/{hash[0:2]}/{hash}.gz
-	key := fmt.Sprintf("blobs/%s/%s.gz", hash[:2], hash)
-
-	result, err := s3Client.GetObject(ctx, &s3.GetObjectInput{
-		Bucket: &bucket,
-		Key:    &key,
-	})
-	if err != nil {
-		return "", fmt.Errorf("failed to get blob from S3: %w", err)
-	}
-	defer result.Body.Close()
-
-	// Decompress gzip
-	gzReader, err := gzip.NewReader(result.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to create gzip reader: %w", err)
-	}
-	defer gzReader.Close()
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [--range start:end] [--org org_id] [--org-scoped-keys] <trace_id>\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "\nExample:\n")
+	fmt.Fprintf(os.Stderr, "  %s 44e0c73c00b2914b0b08945fd2665935\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  %s --range 0:4096 44e0c73c00b2914b0b08945fd2665935\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  %s --org acme 44e0c73c00b2914b0b08945fd2665935\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "\nEnvironment variables:\n")
+	fmt.Fprintf(os.Stderr, "  STORAGE_PROVIDER (default: s3; also s3compat, oss, gcs, fs)\n")
+	fmt.Fprintf(os.Stderr, "  S3_BUCKET (default: traces)\n")
+	fmt.Fprintf(os.Stderr, "  S3_REGION (default: us-east-1)\n")
+	fmt.Fprintf(os.Stderr, "  S3_ENDPOINT (for MinIO/SeaweedFS with s3compat)\n")
+	fmt.Fprintf(os.Stderr, "  INDEXES_PATH (default: indexes/)\n")
+	fmt.Fprintf(os.Stderr, "  BLOBS_PATH (default: blobs/)\n")
+	fmt.Fprintf(os.Stderr, "  DICTS_PATH (default: dicts/; only read if the trace's blobs were compressed against a dictionary)\n")
+	fmt.Fprintf(os.Stderr, "  PACKS_PATH (default: packs/; only read if the trace's chunks were written through a pack.Store)\n")
+	fmt.Fprintf(os.Stderr, "  COMPRESSION (default: zstd; must match the worker's, also gzip or none)\n")
+	fmt.Fprintf(os.Stderr, "  ORG_ID (default for --org)\n")
+	fmt.Fprintf(os.Stderr, "  ORG_SCOPED_KEYS (default for --org-scoped-keys; must match the worker's cfg.OrgScopedKeys)\n")
+}
 
-	var buf bytes.Buffer
-	if _, err := io.Copy(&buf, gzReader); err != nil {
-		return "", fmt.Errorf("failed to decompress blob: %w", err)
+// storageConfig builds a storage.Config for provider from the environment,
+// mirroring the worker's STORAGE_PROVIDER conventions so reconstruct reads
+// the same bucket a worker deployment was configured with.
+func storageConfig(provider string) storage.Config {
+	return storage.Config{
+		Provider: provider,
+		S3: storage.S3Config{
+			Bucket:    getEnv("S3_BUCKET", "traces"),
+			Region:    getEnv("S3_REGION", "us-east-1"),
+			Endpoint:  getEnv("S3_ENDPOINT", ""),
+			PathStyle: provider == "s3compat",
+		},
+		OSS: storage.OSSConfig{
+			Bucket:          getEnv("S3_BUCKET", "traces"),
+			Endpoint:        getEnv("OSS_ENDPOINT", ""),
+			AccessKeyID:     getEnv("OSS_ACCESS_KEY_ID", ""),
+			AccessKeySecret: getEnv("OSS_ACCESS_KEY_SECRET", ""),
+		},
+		GCS: storage.GCSConfig{Bucket: getEnv("GCS_BUCKET", "")},
+		FS:  storage.FSConfig{RootDir: getEnv("FS_ROOT_DIR", "./gravity-data")},
 	}
-
-	return buf.String(), nil
 }
 
 func getEnv(key, defaultValue string) string {
@@ -152,3 +140,15 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getBoolEnv(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return defaultValue
+	}
+	return b
+}
@@ -2,18 +2,48 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/youware/gravity/internal/storage"
 	"github.com/youware/gravity/internal/worker"
+	"github.com/youware/gravity/internal/worker/dict"
+
+	"github.com/youware/gravity/internal/shared/tracing"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "train-dict" {
+		trainDict(os.Args[2:])
+		return
+	}
+
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 	log.Println("Starting Gravity Compression Worker...")
 
+	tracingShutdown, err := tracing.Init(context.Background(), "gravity-worker")
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := tracingShutdown(shutdownCtx); err != nil {
+			log.Printf("error shutting down tracing: %v", err)
+		}
+	}()
+
+	admin := tracing.NewAdminServer(":9091")
+	go func() {
+		if err := admin.Start(); err != nil {
+			log.Printf("admin server error: %v", err)
+		}
+	}()
+
 	// Load configuration
 	cfg, err := worker.LoadConfig()
 	if err != nil {
@@ -57,3 +87,41 @@ func main() {
 
 	log.Println("Shutdown complete")
 }
+
+// trainDict runs a one-shot dictionary training pass for a single org and
+// exits, instead of starting the poll loop. It reuses the same storage
+// config as the worker server so it can be pointed at the same bucket with
+// `gravity-worker train-dict <org_id>`.
+func trainDict(args []string) {
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+	if len(args) < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: gravity-worker train-dict <org_id>")
+		os.Exit(1)
+	}
+	org := args[0]
+
+	cfg, err := worker.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	ctx := context.Background()
+	bucket, err := storage.New(ctx, cfg.StorageConfig())
+	if err != nil {
+		log.Fatalf("Failed to create storage backend: %v", err)
+	}
+
+	store := dict.NewStore(bucket, cfg.DictsPath)
+	trainer := dict.NewTrainer(bucket, cfg.RawSpansPath, store).WithSampleCount(cfg.DictSampleCount)
+
+	manifest, err := trainer.Train(ctx, org)
+	if err != nil {
+		log.Fatalf("Failed to train dictionary for org %s: %v", org, err)
+	}
+
+	fmt.Printf("org_id:            %s\n", manifest.Org)
+	fmt.Printf("active_version:    %s\n", manifest.ActiveVersion)
+	fmt.Printf("sample_count:      %d\n", manifest.SampleCount)
+	fmt.Printf("compression_ratio: %.2f\n", manifest.CompressionRatio)
+}
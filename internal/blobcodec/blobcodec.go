@@ -0,0 +1,182 @@
+// Package blobcodec defines the on-disk encoding the compression worker
+// uses for blob payloads, so the worker and internal/reconstruct (which
+// reads the same objects back) share one encode/decode path instead of
+// each guessing at the other's wire format.
+package blobcodec
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Codec identifies the compression algorithm a blob's body was written
+// with. It's stored as a magic byte at the start of every blob (see
+// Encode) so a reader can dispatch on the data itself instead of trusting
+// the object key's file extension.
+type Codec byte
+
+const (
+	// Zstd compresses with zstd, optionally using a per-org trained
+	// dictionary. This is the worker's default codec.
+	Zstd Codec = 'Z'
+	// Gzip compresses with gzip at the default level. No dictionary
+	// support; mainly useful for deployments that can't take a zstd
+	// dependency or want to compare ratios.
+	Gzip Codec = 'G'
+	// None stores chunks uncompressed.
+	None Codec = 'N'
+)
+
+// Ext returns the blob key file extension conventionally used for c.
+func (c Codec) Ext() string {
+	switch c {
+	case Gzip:
+		return ".gz"
+	case None:
+		return ".raw"
+	default:
+		return ".zst"
+	}
+}
+
+// ContentType returns the MIME type to store a c-compressed blob with.
+func (c Codec) ContentType() string {
+	switch c {
+	case Gzip:
+		return "application/gzip"
+	case None:
+		return "application/octet-stream"
+	default:
+		return "application/zstd"
+	}
+}
+
+// FromConfig maps a worker Config.Compression string to a Codec,
+// defaulting to Zstd for "" or any unrecognized value so existing
+// deployments that never set Compression keep today's behavior.
+func FromConfig(mode string) Codec {
+	switch mode {
+	case "gzip":
+		return Gzip
+	case "none":
+		return None
+	default:
+		return Zstd
+	}
+}
+
+// MagicSize is the fixed-size magic header every blob starts with: byte 0
+// is the Codec, bytes 1-3 are reserved for future use.
+const MagicSize = 4
+
+// Encode prefixes data with a 4-byte codec magic header, and for Zstd a
+// length-delimited dictionary version, so a reader can recover both
+// without trusting the object key. Gzip and None blobs carry no
+// dictionary, since only zstd compresses against one.
+func Encode(codec Codec, dictVersion string, data []byte) []byte {
+	header := []byte{byte(codec), 0, 0, 0}
+	if codec == Zstd {
+		dv := DictVersionOrNone(dictVersion)
+		header = append(header, byte(len(dv)))
+		header = append(header, dv...)
+	}
+	return append(header, data...)
+}
+
+// Decode splits a blob stored by Encode back into its codec, dictionary
+// version (empty outside Zstd), and compressed payload.
+func Decode(blob []byte) (codec Codec, dictVersion string, data []byte, err error) {
+	if len(blob) < MagicSize {
+		return 0, "", nil, errors.New("blobcodec: truncated blob magic header")
+	}
+	codec = Codec(blob[0])
+	rest := blob[MagicSize:]
+
+	if codec != Zstd {
+		return codec, "", rest, nil
+	}
+	if len(rest) < 1 {
+		return 0, "", nil, errors.New("blobcodec: truncated blob dictionary header")
+	}
+	n := int(rest[0])
+	if len(rest) < 1+n {
+		return 0, "", nil, errors.New("blobcodec: truncated blob dictionary header")
+	}
+	return codec, string(rest[1 : 1+n]), rest[1+n:], nil
+}
+
+// DictVersionOrNone normalizes the empty (dictionary-less) version to the
+// sentinel Encode/Decode use on the wire.
+func DictVersionOrNone(dictVersion string) string {
+	if dictVersion == "" {
+		return "none"
+	}
+	return dictVersion
+}
+
+// GzipCompress compresses data with gzip at the default level.
+func GzipCompress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GzipDecompress decompresses a gzip-compressed blob.
+func GzipDecompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ZstdCompress compresses data with zstd, optionally against dict. Callers
+// compressing many chunks against the same dictionary (the worker's
+// Processor) should cache their own *zstd.Encoder instead of paying the
+// dictionary-loading cost per call; this is for one-off encodes.
+func ZstdCompress(data, dict []byte) ([]byte, error) {
+	var opts []zstd.EOption
+	if len(dict) > 0 {
+		opts = append(opts, zstd.WithEncoderDict(dict))
+	}
+	enc, err := zstd.NewWriter(nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer enc.Close()
+
+	return enc.EncodeAll(data, nil), nil
+}
+
+// ZstdDecompress decompresses a zstd-compressed blob, optionally against
+// the dictionary it was compressed with (pass nil for dictionary-less
+// blobs). Readers get the dictionary version to pass here from Decode.
+func ZstdDecompress(data, dict []byte) ([]byte, error) {
+	var opts []zstd.DOption
+	if len(dict) > 0 {
+		opts = append(opts, zstd.WithDecoderDicts(dict))
+	}
+	dec, err := zstd.NewReader(nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer dec.Close()
+
+	return dec.DecodeAll(data, nil)
+}
@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+var keysBucket = []byte("access_keys")
+
+// BoltKeyStore is the default KeyStore, backing access-key entries with a
+// single bbolt file so a standalone ingest deployment doesn't need an
+// external database just to authenticate clients.
+type BoltKeyStore struct {
+	db *bbolt.DB
+}
+
+// NewBoltKeyStore opens (creating if necessary) a bbolt-backed KeyStore at path.
+func NewBoltKeyStore(path string) (*BoltKeyStore, error) {
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("auth: failed to open key store at %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(keysBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("auth: failed to initialize key store bucket: %w", err)
+	}
+
+	return &BoltKeyStore{db: db}, nil
+}
+
+// Generate implements KeyStore.
+func (s *BoltKeyStore) Generate(orgID string, limit RateLimit) (*Key, error) {
+	accessKey, err := generateAccessKey()
+	if err != nil {
+		return nil, fmt.Errorf("auth: failed to generate access key: %w", err)
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("auth: failed to generate secret: %w", err)
+	}
+
+	key := &Key{
+		AccessKey: accessKey,
+		Secret:    secret,
+		OrgID:     orgID,
+		CreatedAt: time.Now(),
+		Limit:     limit,
+	}
+
+	if err := s.put(key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// Lookup implements KeyStore.
+func (s *BoltKeyStore) Lookup(accessKey string) (*Key, error) {
+	var key Key
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		raw := tx.Bucket(keysBucket).Get([]byte(keyNamespace(accessKey)))
+		if raw == nil {
+			return ErrKeyNotFound
+		}
+		return json.Unmarshal(raw, &key)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if key.Revoked {
+		return nil, ErrKeyNotFound
+	}
+
+	return &key, nil
+}
+
+// Revoke implements KeyStore.
+func (s *BoltKeyStore) Revoke(accessKey string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(keysBucket)
+		raw := bucket.Get([]byte(keyNamespace(accessKey)))
+		if raw == nil {
+			return ErrKeyNotFound
+		}
+
+		var key Key
+		if err := json.Unmarshal(raw, &key); err != nil {
+			return err
+		}
+		key.Revoked = true
+
+		encoded, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put([]byte(keyNamespace(accessKey)), encoded)
+	})
+}
+
+// Close implements KeyStore.
+func (s *BoltKeyStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *BoltKeyStore) put(key *Key) error {
+	encoded, err := json.Marshal(key)
+	if err != nil {
+		return fmt.Errorf("auth: failed to encode key: %w", err)
+	}
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(keysBucket).Put([]byte(keyNamespace(key.AccessKey)), encoded)
+	})
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// UnaryServerInterceptor validates the "authorization" gRPC metadata key the
+// same way Wrap validates the HTTP Authorization header, injecting the
+// resolved org_id into the handler's context (retrievable via
+// OrgIDFromContext) instead of the client-supplied x-org-id metadata the
+// OTLP gRPC services otherwise trust unconditionally. Every unary RPC is
+// rejected with Unauthenticated/ResourceExhausted if the metadata is
+// missing, malformed, or the key is unknown/revoked/mismatched/over-limit.
+func (m *Middleware) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		var contentLength int64
+		if msg, ok := req.(proto.Message); ok {
+			contentLength = int64(proto.Size(msg))
+		}
+
+		orgID, err := m.authenticate(authHeaderFromMetadata(md), contentLength)
+		if err != nil {
+			switch {
+			case errors.Is(err, errMalformedAuth), errors.Is(err, ErrKeyNotFound), errors.Is(err, errBadSecret):
+				return nil, status.Error(codes.Unauthenticated, err.Error())
+			case errors.Is(err, errRateLimited), errors.Is(err, errQuotaExceeded):
+				return nil, status.Error(codes.ResourceExhausted, err.Error())
+			default:
+				return nil, status.Error(codes.Internal, "failed to validate access key")
+			}
+		}
+
+		return handler(context.WithValue(ctx, orgIDContextKey, orgID), req)
+	}
+}
+
+// authHeaderFromMetadata returns the incoming "authorization" gRPC metadata
+// value, which clients set to the same "Bearer <access_key>:<secret>" form
+// used for the HTTP Authorization header, so it can be parsed by the same
+// parseBearer.
+func authHeaderFromMetadata(md metadata.MD) string {
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
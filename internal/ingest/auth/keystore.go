@@ -0,0 +1,70 @@
+// Package auth provides access-key authentication and tenant isolation for
+// the OTLP ingest endpoints, replacing the spoofable x-org-id header with a
+// signed credential that resolves to an org_id.
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// ErrKeyNotFound is returned by KeyStore.Lookup when no entry matches the
+// given access key, or the entry has been revoked.
+var ErrKeyNotFound = errors.New("auth: access key not found")
+
+// RateLimit caps the ingest volume a key is allowed, enforced by callers of
+// KeyStore (e.g. AuthMiddleware). A zero value means unlimited.
+type RateLimit struct {
+	RequestsPerSecond int
+	QuotaBytesPerDay  int64
+}
+
+// Key is an access-key/secret pair bound to a single org.
+type Key struct {
+	AccessKey string // 8-char id, safe to log
+	Secret    string // 32-char secret, never logged
+	OrgID     string
+	CreatedAt time.Time
+	Revoked   bool
+	Limit     RateLimit
+}
+
+// KeyStore generates, looks up, and revokes access keys. Implementations
+// must make Lookup safe for concurrent use by request-handling goroutines.
+type KeyStore interface {
+	// Generate creates and persists a new key bound to orgID.
+	Generate(orgID string, limit RateLimit) (*Key, error)
+	// Lookup returns the key for accessKey, or ErrKeyNotFound if it doesn't
+	// exist or has been revoked.
+	Lookup(accessKey string) (*Key, error)
+	// Revoke marks accessKey as revoked; subsequent Lookups fail.
+	Revoke(accessKey string) error
+	// Close releases the underlying storage handle.
+	Close() error
+}
+
+// keyNamespace mirrors the "s3-access-key-<key>" style used by S3-compatible
+// gateways for their own credential stores.
+func keyNamespace(accessKey string) string {
+	return "s3-access-key-" + accessKey
+}
+
+// generateAccessKey returns a random 8-character hex access key id.
+func generateAccessKey() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// generateSecret returns a random 32-character hex secret.
+func generateSecret() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// keyLimiter tracks request-rate and daily-quota state for a single access
+// key, created lazily on first use by limiterSet.get and reused across
+// requests for the life of the process.
+type keyLimiter struct {
+	requests *rate.Limiter // nil when RequestsPerSecond is unlimited
+
+	mu        sync.Mutex
+	quotaDay  string // UTC date ("2006-01-02") quotaUsed applies to
+	quotaUsed int64
+}
+
+// allowRequest reports whether a request is within the key's
+// requests-per-second limit. Always true when RequestsPerSecond is 0
+// (unlimited).
+func (l *keyLimiter) allowRequest() bool {
+	if l.requests == nil {
+		return true
+	}
+	return l.requests.Allow()
+}
+
+// allowBytes reports whether adding n bytes keeps usage within quota,
+// recording the bytes against today's usage if so. Always true when quota
+// is 0 (unlimited). Usage resets automatically at UTC midnight rather than
+// on a rolling 24h window, trading precision for not needing persistent
+// per-key state.
+func (l *keyLimiter) allowBytes(quota, n int64) bool {
+	if quota <= 0 {
+		return true
+	}
+
+	today := time.Now().UTC().Format("2006-01-02")
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.quotaDay != today {
+		l.quotaDay = today
+		l.quotaUsed = 0
+	}
+	if l.quotaUsed+n > quota {
+		return false
+	}
+	l.quotaUsed += n
+	return true
+}
+
+// limiterSet caches a keyLimiter per access key. State is local to one
+// ingest process (not shared via KeyStore) and resets on restart, which is
+// acceptable for rate/quota enforcement that only needs to be approximately
+// right.
+type limiterSet struct {
+	mu       sync.Mutex
+	limiters map[string]*keyLimiter
+}
+
+func newLimiterSet() *limiterSet {
+	return &limiterSet{limiters: make(map[string]*keyLimiter)}
+}
+
+// get returns accessKey's keyLimiter, creating it (and its request-rate
+// limiter, if limit calls for one) on first use.
+func (s *limiterSet) get(accessKey string, limit RateLimit) *keyLimiter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.limiters[accessKey]
+	if !ok {
+		l = &keyLimiter{}
+		s.limiters[accessKey] = l
+	}
+	if limit.RequestsPerSecond > 0 && l.requests == nil {
+		l.requests = rate.NewLimiter(rate.Limit(limit.RequestsPerSecond), limit.RequestsPerSecond)
+	}
+	return l
+}
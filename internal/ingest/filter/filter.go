@@ -1,18 +1,55 @@
 package filter
 
-// Config defines which spans to process based on name and OpenInference span kind.
+import (
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+
+	"github.com/youware/gravity/internal/shared/tracing"
+)
+
+// Config defines which spans to process based on name, OpenInference span
+// kind, and arbitrary CEL predicates.
 //
 // Filtering Behavior:
-// - Spans are first filtered by name (SpanNames)
-// - If a span passes name filter but has no openinference.span.kind attribute,
-//   it will NOT be processed (e.g., vendor-specific instrumentation spans like llm.azure.*)
-// - Only spans with valid OpenInference span kinds (SpanKinds) are processed
+//   - Spans are first filtered by name (SpanNames)
+//   - If a span passes name filter but has no openinference.span.kind attribute,
+//     it will NOT be processed (e.g., vendor-specific instrumentation spans like llm.azure.*)
+//   - Only spans with valid OpenInference span kinds (SpanKinds) are processed
+//   - If Expressions is non-empty, a span must ALSO satisfy at least one
+//     compiled CEL expression (short-circuit OR) to be processed
 //
 // This ensures we only process standardized OpenInference traces and ignore
-// vendor-specific metadata spans that don't conform to the spec.
+// vendor-specific metadata spans that don't conform to the spec, while still
+// letting operators carve out custom predicates without a restart.
 type Config struct {
 	SpanNames []string
 	SpanKinds []string // OpenInference span kinds to process
+
+	// Expressions are CEL predicates evaluated against the span. A span
+	// passes the expression filter if ANY expression evaluates to true.
+	// Available variables: span.name, span.kind, attrs (map[string]string),
+	// resource (map[string]string), duration (milliseconds, int).
+	Expressions []string
+
+	mu       sync.RWMutex
+	programs []cel.Program
+}
+
+var celEnv *cel.Env
+
+func init() {
+	env, err := cel.NewEnv(
+		cel.Variable("span", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("attrs", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("resource", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("duration", cel.IntType),
+	)
+	if err != nil {
+		panic(fmt.Sprintf("filter: failed to build CEL environment: %v", err))
+	}
+	celEnv = env
 }
 
 // Default returns the default filter configuration
@@ -36,6 +73,70 @@ func Default() *Config {
 	}
 }
 
+// New builds a Config from explicit span names, span kinds, and CEL
+// expressions, compiling the expressions up front so evaluation errors
+// surface at startup/reload time rather than per-span.
+func New(spanNames, spanKinds, expressions []string) (*Config, error) {
+	c := &Config{
+		SpanNames: spanNames,
+		SpanKinds: spanKinds,
+	}
+
+	if err := c.setExpressions(expressions); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// compileExpressions builds a cel.Program per expression. It takes
+// expressions as a local parameter rather than reading a shared Config
+// field, so callers racing on Config.Expressions can't observe a
+// half-updated slice mid-compile.
+func compileExpressions(expressions []string) ([]cel.Program, error) {
+	programs := make([]cel.Program, 0, len(expressions))
+
+	for _, expr := range expressions {
+		ast, issues := celEnv.Compile(expr)
+		if issues != nil && issues.Err() != nil {
+			return nil, fmt.Errorf("filter: failed to compile expression %q: %w", expr, issues.Err())
+		}
+
+		program, err := celEnv.Program(ast)
+		if err != nil {
+			return nil, fmt.Errorf("filter: failed to build program for expression %q: %w", expr, err)
+		}
+
+		programs = append(programs, program)
+	}
+
+	return programs, nil
+}
+
+// setExpressions compiles expressions and, only if compilation succeeds,
+// publishes both Expressions and the compiled programs together under c.mu
+// so a concurrent reader never observes one updated without the other.
+func (c *Config) setExpressions(expressions []string) error {
+	programs, err := compileExpressions(expressions)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.Expressions = expressions
+	c.programs = programs
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Reload recompiles the filter with a new set of CEL expressions, leaving
+// the previously-compiled programs (and Expressions) in place if compilation
+// fails so a bad reload doesn't take the filter offline.
+func (c *Config) Reload(expressions []string) error {
+	return c.setExpressions(expressions)
+}
+
 // ShouldProcess checks if a span should be processed based on its name
 func (c *Config) ShouldProcess(spanName string) bool {
 	for _, name := range c.SpanNames {
@@ -60,3 +161,41 @@ func (c *Config) ShouldProcessKind(spanKind string) bool {
 	}
 	return false
 }
+
+// ShouldProcessExpr evaluates the compiled CEL expressions against a span,
+// short-circuit OR'ing across rules. If no expressions are configured, it
+// allows all spans through (the expression filter is opt-in).
+func (c *Config) ShouldProcessExpr(spanName, spanKind string, attrs, resource map[string]string, durationMs int64) bool {
+	c.mu.RLock()
+	programs := c.programs
+	c.mu.RUnlock()
+
+	if len(programs) == 0 {
+		return true
+	}
+
+	vars := map[string]any{
+		"span": map[string]any{
+			"name": spanName,
+			"kind": spanKind,
+		},
+		"attrs":    attrs,
+		"resource": resource,
+		"duration": durationMs,
+	}
+
+	for _, program := range programs {
+		out, _, err := program.Eval(vars)
+		if err != nil {
+			tracing.FilterDropReasons.WithLabelValues("expr_eval_error").Inc()
+			continue
+		}
+
+		if result, ok := out.Value().(bool); ok && result {
+			return true
+		}
+	}
+
+	tracing.FilterDropReasons.WithLabelValues("expr_no_match").Inc()
+	return false
+}
@@ -0,0 +1,30 @@
+package http
+
+import "context"
+
+// requestMetrics accumulates the counters a handler discovers while
+// processing a request, so requestLogger can emit them as part of the
+// single structured access-log record instead of each handler logging its
+// own ad-hoc summary line.
+type requestMetrics struct {
+	OrgID            string
+	DecompressedSize int
+	SpanCount        int
+	MetricCount      int
+}
+
+type requestMetricsKey struct{}
+
+// withRequestMetrics attaches a fresh *requestMetrics to ctx and returns
+// both, so a middleware can read back whatever a handler recorded into it.
+func withRequestMetrics(ctx context.Context) (context.Context, *requestMetrics) {
+	m := &requestMetrics{}
+	return context.WithValue(ctx, requestMetricsKey{}, m), m
+}
+
+// requestMetricsFromContext returns the *requestMetrics attached by
+// withRequestMetrics, or nil if none is present (e.g. a non-ingest route).
+func requestMetricsFromContext(ctx context.Context) *requestMetrics {
+	m, _ := ctx.Value(requestMetricsKey{}).(*requestMetrics)
+	return m
+}
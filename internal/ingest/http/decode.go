@@ -0,0 +1,321 @@
+package http
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	collectormetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	collectortracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxDecompressedBytes bounds how large a request body is allowed to grow
+// to once decompressed, guarding against decompression bombs sent with a
+// small Content-Encoding: gzip/zstd body.
+const maxDecompressedBytes = 64 << 20 // 64 MiB
+
+// errBodyTooLarge is returned by a limitedBody once its backing reader has
+// produced more than maxDecompressedBytes of decompressed data.
+var errBodyTooLarge = errors.New("decompressed request body exceeds maximum allowed size")
+
+// zstdDecoderPool reuses *zstd.Decoder instances across requests via
+// Reset, avoiding the allocation of a fresh decoder (and its window buffer)
+// per request.
+var zstdDecoderPool = sync.Pool{
+	New: func() any {
+		dec, err := zstd.NewReader(nil)
+		if err != nil {
+			// Only fails for invalid options, none of which we pass.
+			panic(fmt.Sprintf("decode: failed to construct zstd decoder: %v", err))
+		}
+		return dec
+	},
+}
+
+// BodyMiddleware bounds the raw (pre-decompression) request body to
+// maxBodyBytes via http.MaxBytesReader, then negotiates the body's framing:
+// AWS `aws-chunked` content-encoding (used by S3-compatible clients that
+// forward OTLP payloads directly) is unwrapped first, followed by gzip or
+// zstd Content-Encoding. The handler always sees a plain io.ReadCloser whose
+// decompressed size is capped at maxDecompressedBytes; overflow at either
+// stage surfaces as errBodyTooLarge, which the handlers map to a 413.
+func BodyMiddleware(maxBodyBytes int64) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if maxBodyBytes > 0 {
+				r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+			}
+
+			encodings := splitEncodings(r.Header.Get("Content-Encoding"))
+			for i := len(encodings) - 1; i >= 0; i-- {
+				switch encodings[i] {
+				case "aws-chunked":
+					r.Body = io.NopCloser(newAWSChunkedReader(r.Body))
+
+				case "gzip":
+					gz, err := gzip.NewReader(r.Body)
+					if err != nil {
+						slog.Error("failed to create gzip reader", "error", err, "path", r.URL.Path)
+						http.Error(w, "Invalid gzip encoding", http.StatusBadRequest)
+						return
+					}
+					r.Body = newLimitedBody(gz, maxDecompressedBytes)
+
+				case "zstd":
+					dec := zstdDecoderPool.Get().(*zstd.Decoder)
+					if err := dec.Reset(r.Body); err != nil {
+						zstdDecoderPool.Put(dec)
+						slog.Error("failed to create zstd reader", "error", err, "path", r.URL.Path)
+						http.Error(w, "Invalid zstd encoding", http.StatusBadRequest)
+						return
+					}
+					r.Body = newLimitedBody(&pooledZstdDecoder{dec: dec}, maxDecompressedBytes)
+				}
+			}
+			r.Header.Del("Content-Encoding")
+
+			next(w, r)
+		}
+	}
+}
+
+// splitEncodings parses a (possibly multi-valued, comma-separated)
+// Content-Encoding header into its individual codings, outermost last, the
+// order they were applied in.
+func splitEncodings(header string) []string {
+	if header == "" {
+		return nil
+	}
+	parts := strings.Split(header, ",")
+	encodings := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			encodings = append(encodings, p)
+		}
+	}
+	return encodings
+}
+
+// awsChunkedReader unwraps the AWS `aws-chunked` content encoding used by S3
+// PutObject streaming uploads: each chunk is framed as
+// "<hex-size>[;chunk-signature=...]\r\n<data>\r\n", terminated by a
+// zero-size chunk. Chunk signatures are present for SigV4 streaming
+// signing but aren't validated here — this middleware trusts that request
+// authentication already happened upstream (e.g. the access-key middleware).
+type awsChunkedReader struct {
+	src       *bufio.Reader
+	remaining int64 // bytes left to read in the current chunk's data
+	done      bool
+}
+
+func newAWSChunkedReader(r io.Reader) *awsChunkedReader {
+	return &awsChunkedReader{src: bufio.NewReader(r)}
+}
+
+func (c *awsChunkedReader) Read(p []byte) (int, error) {
+	if c.done {
+		return 0, io.EOF
+	}
+
+	if c.remaining == 0 {
+		size, err := c.readChunkHeader()
+		if err != nil {
+			return 0, err
+		}
+		if size == 0 {
+			c.done = true
+			return 0, io.EOF
+		}
+		c.remaining = size
+	}
+
+	if int64(len(p)) > c.remaining {
+		p = p[:c.remaining]
+	}
+	n, err := c.src.Read(p)
+	c.remaining -= int64(n)
+	if err == nil && c.remaining == 0 {
+		// consume the trailing CRLF after this chunk's data
+		if _, discardErr := c.src.Discard(2); discardErr != nil {
+			return n, fmt.Errorf("decode: aws-chunked: reading chunk trailer: %w", discardErr)
+		}
+	}
+	return n, err
+}
+
+// readChunkHeader reads a "<hex-size>[;chunk-signature=...]\r\n" line and
+// returns the chunk's data size.
+func (c *awsChunkedReader) readChunkHeader() (int64, error) {
+	line, err := c.src.ReadString('\n')
+	if err != nil {
+		return 0, fmt.Errorf("decode: aws-chunked: reading chunk header: %w", err)
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if idx := strings.IndexByte(line, ';'); idx != -1 {
+		line = line[:idx]
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(line), 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("decode: aws-chunked: invalid chunk size %q: %w", line, err)
+	}
+	return size, nil
+}
+
+// pooledZstdDecoder adapts a pooled *zstd.Decoder to io.ReadCloser, handing
+// the decoder back to zstdDecoderPool on Close instead of freeing it.
+type pooledZstdDecoder struct {
+	dec *zstd.Decoder
+}
+
+func (p *pooledZstdDecoder) Read(buf []byte) (int, error) { return p.dec.Read(buf) }
+
+func (p *pooledZstdDecoder) Close() error {
+	p.dec.Reset(nil)
+	zstdDecoderPool.Put(p.dec)
+	return nil
+}
+
+// limitedBody wraps a decompressed request body, surfacing errBodyTooLarge
+// once more than limit bytes have been read instead of silently truncating
+// or growing without bound.
+type limitedBody struct {
+	src   io.Reader
+	inner io.Closer
+	limit int64
+	read  int64
+}
+
+func newLimitedBody(r io.ReadCloser, limit int64) io.ReadCloser {
+	return &limitedBody{src: r, inner: r, limit: limit}
+}
+
+func (lb *limitedBody) Read(p []byte) (int, error) {
+	if lb.read > lb.limit {
+		return 0, errBodyTooLarge
+	}
+	n, err := lb.src.Read(p)
+	lb.read += int64(n)
+	if lb.read > lb.limit {
+		return n, errBodyTooLarge
+	}
+	return n, err
+}
+
+func (lb *limitedBody) Close() error { return lb.inner.Close() }
+
+// isBodyTooLarge reports whether err indicates the request body exceeded an
+// allowed size, whether that's http.MaxBytesReader's pre-decompression cap
+// or limitedBody's post-decompression cap.
+func isBodyTooLarge(err error) bool {
+	if errors.Is(err, errBodyTooLarge) {
+		return true
+	}
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+// isJSONContentType reports whether contentType negotiates the OTLP/JSON
+// encoding rather than the default OTLP/protobuf encoding.
+func isJSONContentType(contentType string) bool {
+	mediaType := contentType
+	if idx := strings.Index(mediaType, ";"); idx != -1 {
+		mediaType = mediaType[:idx]
+	}
+	return strings.TrimSpace(mediaType) == "application/json"
+}
+
+// decodeTraceRequest parses body as either OTLP/protobuf or OTLP/JSON,
+// dispatching on contentType.
+func decodeTraceRequest(body []byte, contentType string) (*collectortracepb.ExportTraceServiceRequest, error) {
+	var req collectortracepb.ExportTraceServiceRequest
+	if isJSONContentType(contentType) {
+		if err := protojson.Unmarshal(body, &req); err != nil {
+			return nil, fmt.Errorf("unmarshal JSON OTLP trace request: %w", err)
+		}
+		return &req, nil
+	}
+	if err := proto.Unmarshal(body, &req); err != nil {
+		return nil, fmt.Errorf("unmarshal protobuf OTLP trace request: %w", err)
+	}
+	return &req, nil
+}
+
+// decodeMetricsRequest parses body as either OTLP/protobuf or OTLP/JSON,
+// dispatching on contentType.
+func decodeMetricsRequest(body []byte, contentType string) (*collectormetricspb.ExportMetricsServiceRequest, error) {
+	var req collectormetricspb.ExportMetricsServiceRequest
+	if isJSONContentType(contentType) {
+		if err := protojson.Unmarshal(body, &req); err != nil {
+			return nil, fmt.Errorf("unmarshal JSON OTLP metrics request: %w", err)
+		}
+		return &req, nil
+	}
+	if err := proto.Unmarshal(body, &req); err != nil {
+		return nil, fmt.Errorf("unmarshal protobuf OTLP metrics request: %w", err)
+	}
+	return &req, nil
+}
+
+// writeTraceResponse writes an OTLP ExportTraceServiceResponse encoded to
+// match contentType, including a PartialSuccess summary when rejectedSpans
+// is non-zero so clients can tell some spans were dropped during
+// conversion without the whole batch failing.
+func writeTraceResponse(w http.ResponseWriter, contentType string, rejectedSpans int64, errorMessage string) {
+	resp := &collectortracepb.ExportTraceServiceResponse{}
+	if rejectedSpans > 0 {
+		resp.PartialSuccess = &collectortracepb.ExportTracePartialSuccess{
+			RejectedSpans: rejectedSpans,
+			ErrorMessage:  errorMessage,
+		}
+	}
+	writeOTLPResponse(w, contentType, resp)
+}
+
+// writeMetricsResponse writes an OTLP ExportMetricsServiceResponse encoded
+// to match contentType, including a PartialSuccess summary when
+// rejectedDataPoints is non-zero.
+func writeMetricsResponse(w http.ResponseWriter, contentType string, rejectedDataPoints int64, errorMessage string) {
+	resp := &collectormetricspb.ExportMetricsServiceResponse{}
+	if rejectedDataPoints > 0 {
+		resp.PartialSuccess = &collectormetricspb.ExportMetricsPartialSuccess{
+			RejectedDataPoints: rejectedDataPoints,
+			ErrorMessage:       errorMessage,
+		}
+	}
+	writeOTLPResponse(w, contentType, resp)
+}
+
+func writeOTLPResponse(w http.ResponseWriter, contentType string, msg proto.Message) {
+	if isJSONContentType(contentType) {
+		data, err := protojson.Marshal(msg)
+		if err != nil {
+			slog.Error("failed to marshal OTLP JSON response", "error", err)
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(data)
+		return
+	}
+
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		slog.Error("failed to marshal OTLP protobuf response", "error", err)
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
@@ -0,0 +1,163 @@
+package http
+
+import (
+	"context"
+	"log/slog"
+	"net"
+
+	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/encoding/gzip" // registers the gzip compressor so clients can negotiate it
+	"google.golang.org/grpc/metadata"
+
+	collectorlogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	collectormetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	collectortracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+
+	"github.com/youware/gravity/internal/ingest/auth"
+)
+
+// orgIDFromContext returns the org_id auth.UnaryServerInterceptor resolved
+// from the access key, if auth is enabled. Otherwise it falls back to the
+// client-supplied x-org-id gRPC metadata, mirroring the x-org-id HTTP header
+// the HTTP handlers trust under the same condition.
+func orgIDFromContext(ctx context.Context) string {
+	if orgID, ok := auth.OrgIDFromContext(ctx); ok {
+		return orgID
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "default"
+	}
+
+	values := md.Get("x-org-id")
+	if len(values) == 0 || values[0] == "" {
+		return "default"
+	}
+
+	return values[0]
+}
+
+// otlpTraceServer implements collectortracepb.TraceServiceServer on top of
+// the same Handler used by the HTTP transport, so both paths share
+// filtering, conversion, and batching behavior.
+type otlpTraceServer struct {
+	collectortracepb.UnimplementedTraceServiceServer
+
+	handler *Handler
+}
+
+// Export implements collectortracepb.TraceServiceServer
+func (s *otlpTraceServer) Export(ctx context.Context, req *collectortracepb.ExportTraceServiceRequest) (*collectortracepb.ExportTraceServiceResponse, error) {
+	orgID := orgIDFromContext(ctx)
+
+	envelopes, rejected := s.handler.convertOTLPToEnvelopes(ctx, orgID, req)
+
+	for i, envelope := range envelopes {
+		slog.Info("trace envelope summary (grpc)", "org_id", orgID, "index", i+1)
+		slog.Info(envelope.LogSummary())
+	}
+
+	resp := &collectortracepb.ExportTraceServiceResponse{}
+	if rejected > 0 {
+		resp.PartialSuccess = &collectortracepb.ExportTracePartialSuccess{
+			RejectedSpans: rejected,
+			ErrorMessage:  "span missing trace_id or span_id",
+		}
+	}
+
+	return resp, nil
+}
+
+// otlpMetricsServer implements collectormetricspb.MetricsServiceServer,
+// mirroring HandleMetrics' summary logging.
+type otlpMetricsServer struct {
+	collectormetricspb.UnimplementedMetricsServiceServer
+
+	handler *Handler
+}
+
+// Export implements collectormetricspb.MetricsServiceServer
+func (s *otlpMetricsServer) Export(ctx context.Context, req *collectormetricspb.ExportMetricsServiceRequest) (*collectormetricspb.ExportMetricsServiceResponse, error) {
+	orgID := orgIDFromContext(ctx)
+
+	metricsCount := 0
+	for _, rm := range req.GetResourceMetrics() {
+		for _, sm := range rm.GetScopeMetrics() {
+			metricsCount += len(sm.GetMetrics())
+		}
+	}
+
+	slog.Info("received OTLP metrics payload (grpc)", "org_id", orgID, "metrics_count", metricsCount)
+
+	return &collectormetricspb.ExportMetricsServiceResponse{}, nil
+}
+
+// otlpLogsServer implements collectorlogspb.LogsServiceServer. Gravity has
+// no log pipeline yet, so Export only counts and logs the payload, mirroring
+// otlpMetricsServer until a logs envelope type exists.
+type otlpLogsServer struct {
+	collectorlogspb.UnimplementedLogsServiceServer
+
+	handler *Handler
+}
+
+// Export implements collectorlogspb.LogsServiceServer
+func (s *otlpLogsServer) Export(ctx context.Context, req *collectorlogspb.ExportLogsServiceRequest) (*collectorlogspb.ExportLogsServiceResponse, error) {
+	orgID := orgIDFromContext(ctx)
+
+	recordCount := 0
+	for _, rl := range req.GetResourceLogs() {
+		for _, sl := range rl.GetScopeLogs() {
+			recordCount += len(sl.GetLogRecords())
+		}
+	}
+
+	slog.Info("received OTLP logs payload (grpc)", "org_id", orgID, "record_count", recordCount)
+
+	return &collectorlogspb.ExportLogsServiceResponse{}, nil
+}
+
+// GRPCServer wraps the gRPC listener serving the OTLP trace/metrics services.
+type GRPCServer struct {
+	server   *grpc.Server
+	listener net.Listener
+	address  string
+}
+
+// newGRPCServer creates a gRPC server sharing the given Handler's conversion
+// and filtering path. authMiddleware is non-nil when cfg.Auth.Enabled, in
+// which case every RPC is gated behind its UnaryServerInterceptor the same
+// way traces/metrics are gated behind Middleware.Wrap on the HTTP side.
+func newGRPCServer(address string, handler *Handler, authMiddleware *auth.Middleware) (*GRPCServer, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []grpc.ServerOption
+	if authMiddleware != nil {
+		opts = append(opts, grpc.UnaryInterceptor(authMiddleware.UnaryServerInterceptor()))
+	}
+
+	grpcServer := grpc.NewServer(opts...)
+	collectortracepb.RegisterTraceServiceServer(grpcServer, &otlpTraceServer{handler: handler})
+	collectormetricspb.RegisterMetricsServiceServer(grpcServer, &otlpMetricsServer{handler: handler})
+	collectorlogspb.RegisterLogsServiceServer(grpcServer, &otlpLogsServer{handler: handler})
+
+	return &GRPCServer{
+		server:   grpcServer,
+		listener: listener,
+		address:  address,
+	}, nil
+}
+
+// Serve blocks accepting OTLP gRPC connections until Stop is called.
+func (g *GRPCServer) Serve() error {
+	return g.server.Serve(g.listener)
+}
+
+// Stop gracefully stops the gRPC server.
+func (g *GRPCServer) Stop() {
+	g.server.GracefulStop()
+}
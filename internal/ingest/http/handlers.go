@@ -1,92 +1,128 @@
 package http
 
 import (
-	"compress/gzip"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 
-	collectormetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
-	collectortracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
-	"google.golang.org/protobuf/encoding/protojson"
-	"google.golang.org/protobuf/proto"
+	"github.com/go-chi/chi/v5/middleware"
 
+	"github.com/youware/gravity/internal/ingest/auth"
 	"github.com/youware/gravity/internal/ingest/filter"
 	"github.com/youware/gravity/internal/ingest/pipeline"
+	"github.com/youware/gravity/internal/ingest/reproducer"
+	"github.com/youware/gravity/internal/queue"
 	"github.com/youware/gravity/internal/shared/config"
 )
 
+// orgIDFromRequest returns the org_id resolved by auth.Middleware if the
+// request passed through it, falling back to the client-supplied x-org-id
+// header when access-key auth is disabled.
+func orgIDFromRequest(r *http.Request) string {
+	if orgID, ok := auth.OrgIDFromContext(r.Context()); ok {
+		return orgID
+	}
+
+	orgID := r.Header.Get("x-org-id")
+	if orgID == "" {
+		orgID = "default"
+	}
+	return orgID
+}
+
 // Handler handles HTTP requests for OTLP ingestion
 type Handler struct {
 	config     *config.Config
 	batch      *pipeline.Batch
 	spanFilter *filter.Config
+	producer   queue.Producer       // optional; publishes envelopes in addition to in-process handling
+	reproducer *reproducer.Capturer // optional; captures failing/sampled requests for replay
 }
 
 // NewHandler creates a new handler instance
 func NewHandler(cfg *config.Config) *Handler {
-	return &Handler{
+	spanFilter := filter.Default()
+	if len(cfg.Filter.Expressions) > 0 {
+		if err := spanFilter.Reload(cfg.Filter.Expressions); err != nil {
+			slog.Error("failed to compile configured filter expressions, starting without them", "error", err)
+		}
+	}
+
+	h := &Handler{
 		config:     cfg,
 		batch:      pipeline.NewBatch(),
-		spanFilter: filter.Default(),
+		spanFilter: spanFilter,
 	}
-}
 
-// DecompressionMiddleware automatically decompresses request bodies based on Content-Encoding header
-// This middleware wraps handlers and transparently handles gzip-encoded requests
-func DecompressionMiddleware(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// Check if request body is gzip-compressed
-		if r.Header.Get("Content-Encoding") == "gzip" {
-			gz, err := gzip.NewReader(r.Body)
-			if err != nil {
-				slog.Error("failed to create gzip reader", "error", err, "path", r.URL.Path)
-				http.Error(w, "Invalid gzip encoding", http.StatusBadRequest)
-				return
-			}
-			defer gz.Close()
-
-			// Replace request body with decompressed reader
-			// The original r.Body will be closed by the gzip reader
-			r.Body = gz
-
-			// Remove Content-Encoding header so handlers don't need to know about compression
-			r.Header.Del("Content-Encoding")
+	if cfg.Repro.Enabled {
+		sink, err := reproducer.NewLocalSink(cfg.Repro.Dir)
+		if err != nil {
+			slog.Error("failed to create reproducer sink, request capture disabled", "error", err)
+		} else {
+			h.reproducer = reproducer.New(reproducer.Config{
+				Enabled:    true,
+				SampleRate: cfg.Repro.SampleRate,
+			}, sink)
 		}
+	}
 
-		// Call the next handler
-		next(w, r)
+	if cfg.Queue.Enabled {
+		producer, err := queue.NewProducer(queue.Config{
+			Type:          cfg.Queue.Type,
+			Brokers:       cfg.Queue.Brokers,
+			Topic:         cfg.Queue.Topic,
+			ConsumerGroup: cfg.Queue.ConsumerGroup,
+			MaxRetries:    cfg.Queue.MaxRetries,
+		})
+		if err != nil {
+			slog.Error("failed to create queue producer, falling back to in-process pipeline only", "error", err)
+		} else {
+			h.producer = producer
+		}
 	}
+
+	return h
 }
 
-// HandleTraces processes incoming OTLP trace data
+// HandleTraces processes incoming OTLP trace data. The request body may be
+// OTLP/protobuf or OTLP/JSON (dispatched on Content-Type), and may arrive
+// gzip- or zstd-compressed if routed through BodyMiddleware.
 func (h *Handler) HandleTraces(w http.ResponseWriter, r *http.Request) {
+	contentType := r.Header.Get("Content-Type")
+
 	body, err := io.ReadAll(r.Body)
 	defer r.Body.Close()
 	if err != nil {
+		if isBodyTooLarge(err) {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
 		slog.Error("failed to read trace request body", "error", err, "path", r.URL.Path)
 		http.Error(w, "Failed to read request body", http.StatusBadRequest)
 		return
 	}
 
-	// Extract org_id from header for multi-tenancy
-	orgID := r.Header.Get("x-org-id")
-	if orgID == "" {
-		orgID = "default"
+	orgID := orgIDFromRequest(r)
+	if metrics := requestMetricsFromContext(r.Context()); metrics != nil {
+		metrics.OrgID = orgID
+		metrics.DecompressedSize = len(body)
 	}
 
-	// Decode OTLP protobuf
-	var exportReq collectortracepb.ExportTraceServiceRequest
-	if err := proto.Unmarshal(body, &exportReq); err != nil {
-		slog.Error("failed to unmarshal OTLP trace data", "error", err, "org_id", orgID)
-		http.Error(w, "Invalid OTLP protobuf data", http.StatusBadRequest)
+	exportReq, err := decodeTraceRequest(body, contentType)
+	if err != nil {
+		slog.Error("failed to decode OTLP trace request", "error", err, "org_id", orgID, "content_type", contentType)
+		h.captureFailedRequest(r, body, orgID, "trace_decode_error")
+		http.Error(w, "Invalid OTLP trace data", http.StatusBadRequest)
 		return
 	}
 
 	// Process each span and convert to envelopes
-	envelopes := h.convertOTLPToEnvelopes(orgID, &exportReq)
+	envelopes, rejectedSpans := h.convertOTLPToEnvelopes(r.Context(), orgID, exportReq)
+	if metrics := requestMetricsFromContext(r.Context()); metrics != nil {
+		metrics.SpanCount = len(envelopes)
+	}
 
 	// Log envelope details for debugging
 	for i, envelope := range envelopes {
@@ -96,66 +132,113 @@ func (h *Handler) HandleTraces(w http.ResponseWriter, r *http.Request) {
 		// slog.Info("trace envelope summary", "org_id", orgID, "index", i+1, "summary", envelope.LogSummary())
 	}
 
-	// Acknowledge receipt
-	w.WriteHeader(http.StatusAccepted)
+	if h.producer != nil && len(envelopes) > 0 {
+		batch := pipeline.NewBatch()
+		batch.Envelopes = envelopes
+		if err := h.producer.Publish(r.Context(), batch); err != nil {
+			slog.Error("failed to publish envelopes to queue", "error", err, "org_id", orgID)
+		}
+	}
+
+	errorMessage := ""
+	if rejectedSpans > 0 {
+		errorMessage = "span missing trace_id or span_id"
+		h.captureFailedRequest(r, body, orgID, "trace_rejected_spans")
+	}
+	writeTraceResponse(w, contentType, rejectedSpans, errorMessage)
+}
+
+// filterReloadRequest is the body accepted by PUT /admin/filter
+type filterReloadRequest struct {
+	Expressions []string `json:"expressions"`
+}
+
+// HandleFilterReload replaces the span filter's CEL expressions without
+// requiring a restart. It's also invoked internally on SIGHUP using the
+// expressions from the process's original configuration.
+func (h *Handler) HandleFilterReload(w http.ResponseWriter, r *http.Request) {
+	var req filterReloadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.spanFilter.Reload(req.Expressions); err != nil {
+		slog.Error("failed to reload filter expressions", "error", err)
+		http.Error(w, fmt.Sprintf("failed to compile expressions: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	slog.Info("reloaded filter expressions", "count", len(req.Expressions))
+	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]any{
-		"status":     "accepted",
-		"span_count": len(envelopes),
-		"org_id":     orgID,
+		"status":           "reloaded",
+		"expression_count": len(req.Expressions),
 	})
 }
 
-// HandleMetrics processes incoming OTLP metrics data
+// HandleMetrics processes incoming OTLP metrics data. Like HandleTraces, the
+// request body may be OTLP/protobuf or OTLP/JSON and may arrive compressed.
 func (h *Handler) HandleMetrics(w http.ResponseWriter, r *http.Request) {
+	contentType := r.Header.Get("Content-Type")
+
 	body, err := io.ReadAll(r.Body)
 	defer r.Body.Close()
 	if err != nil {
+		if isBodyTooLarge(err) {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
 		slog.Error("failed to read metrics request body", "error", err, "path", r.URL.Path)
 		http.Error(w, "Failed to read request body", http.StatusBadRequest)
 		return
 	}
 
-	// Extract org_id from header
-	orgID := r.Header.Get("x-org-id")
-	if orgID == "" {
-		orgID = "default"
-	}
-
-	// Decode OTLP metrics protobuf
-	var exportReq collectormetricspb.ExportMetricsServiceRequest
-	if err := proto.Unmarshal(body, &exportReq); err != nil {
-		slog.Error("failed to unmarshal OTLP metrics data", "error", err, "org_id", orgID)
-		http.Error(w, "Invalid OTLP protobuf data", http.StatusBadRequest)
-		return
+	orgID := orgIDFromRequest(r)
+	if metrics := requestMetricsFromContext(r.Context()); metrics != nil {
+		metrics.OrgID = orgID
+		metrics.DecompressedSize = len(body)
 	}
 
-	// Convert to JSON for readable logging
-	marshaler := protojson.MarshalOptions{
-		// Multiline:       true,
-		// Indent:          "  ",
-		// EmitUnpopulated: false,
-	}
-	jsonData, err := marshaler.Marshal(&exportReq)
+	exportReq, err := decodeMetricsRequest(body, contentType)
 	if err != nil {
-		slog.Error("failed to marshal metrics payload", "error", err, "org_id", orgID)
-	} else {
-		slog.Info("received OTLP metrics payload", "org_id", orgID)
-		fmt.Println(string(jsonData))
+		slog.Error("failed to decode OTLP metrics request", "error", err, "org_id", orgID, "content_type", contentType)
+		h.captureFailedRequest(r, body, orgID, "metrics_decode_error")
+		http.Error(w, "Invalid OTLP metrics data", http.StatusBadRequest)
+		return
 	}
 
-	// Count metrics for summary
+	// Count metrics for the access log; the structured access-log record
+	// written by requestLogger replaces logging the raw JSON payload here.
 	metricsCount := 0
 	for _, rm := range exportReq.GetResourceMetrics() {
 		for _, sm := range rm.GetScopeMetrics() {
 			metricsCount += len(sm.GetMetrics())
 		}
 	}
+	if metrics := requestMetricsFromContext(r.Context()); metrics != nil {
+		metrics.MetricCount = metricsCount
+	}
 
-	// Acknowledge receipt
-	w.WriteHeader(http.StatusAccepted)
-	json.NewEncoder(w).Encode(map[string]any{
-		"status":        "accepted",
-		"metrics_count": metricsCount,
-		"org_id":        orgID,
-	})
+	writeMetricsResponse(w, contentType, 0, "")
+}
+
+// captureFailedRequest persists the raw request body and metadata via the
+// configured reproducer so it can be replayed later, e.g. with
+// cmd/gravity-replay. It's a no-op when the reproducer is disabled; capture
+// failures are logged rather than surfaced to the caller, since a capture
+// failure should never affect the response sent for the original request.
+func (h *Handler) captureFailedRequest(r *http.Request, body []byte, orgID, reason string) {
+	if h.reproducer == nil || !h.reproducer.ShouldCapture(true) {
+		return
+	}
+
+	requestID := middleware.GetReqID(r.Context())
+	if requestID == "" {
+		requestID = reason
+	}
+
+	if err := h.reproducer.Capture(r.Context(), r, body, orgID, requestID, reason); err != nil {
+		slog.Error("failed to capture request for replay", "error", err, "org_id", orgID, "reason", reason)
+	}
 }
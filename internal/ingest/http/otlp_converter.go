@@ -1,19 +1,38 @@
 package http
 
 import (
+	"context"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 
 	collectortracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
 	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
 	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
 
 	"github.com/youware/gravity/internal/ingest/pipeline"
+	"github.com/youware/gravity/internal/shared/tracing"
 )
 
-// convertOTLPToEnvelopes converts OTLP trace data to Gravity envelopes
-func (h *Handler) convertOTLPToEnvelopes(orgID string, req *collectortracepb.ExportTraceServiceRequest) []pipeline.Envelope {
+// convertOTLPToEnvelopes converts OTLP trace data to Gravity envelopes.
+// It also returns the number of spans rejected outright (as opposed to
+// filtered by configuration) so callers can surface an OTLP-spec
+// partial-success response instead of failing the whole batch.
+func (h *Handler) convertOTLPToEnvelopes(ctx context.Context, orgID string, req *collectortracepb.ExportTraceServiceRequest) ([]pipeline.Envelope, int64) {
+	start := time.Now()
+
+	_, span := otel.Tracer(tracing.TracerName).Start(ctx, "convertOTLPToEnvelopes")
+	defer span.End()
+
 	var envelopes []pipeline.Envelope
+	filtered := 0
+	var rejected int64
 
 	// Iterate through resource spans
 	for _, resourceSpan := range req.GetResourceSpans() {
@@ -24,8 +43,16 @@ func (h *Handler) convertOTLPToEnvelopes(orgID string, req *collectortracepb.Exp
 		for _, scopeSpan := range resourceSpan.GetScopeSpans() {
 			// Iterate through individual spans
 			for _, span := range scopeSpan.GetSpans() {
+				// Reject: a span without identifiers can't be stored or traced back to
+				if len(span.GetTraceId()) == 0 || len(span.GetSpanId()) == 0 {
+					rejected++
+					continue
+				}
+
 				// Filter: only process configured span names
 				if !h.spanFilter.ShouldProcess(span.GetName()) {
+					filtered++
+					tracing.FilterDropReasons.WithLabelValues("span_name").Inc()
 					continue
 				}
 
@@ -36,6 +63,18 @@ func (h *Handler) convertOTLPToEnvelopes(orgID string, req *collectortracepb.Exp
 				// Filter: only process spans with valid OpenInference span kinds
 				// This filters out vendor-specific spans (e.g., llm.azure.*) that don't have span_kind
 				if !h.spanFilter.ShouldProcessKind(spanKind) {
+					filtered++
+					tracing.FilterDropReasons.WithLabelValues("span_kind").Inc()
+					continue
+				}
+
+				// Filter: evaluate any operator-configured CEL expressions
+				durationMs := int64(0)
+				if end, start := span.GetEndTimeUnixNano(), span.GetStartTimeUnixNano(); end > start {
+					durationMs = int64((end - start) / 1_000_000)
+				}
+				if !h.spanFilter.ShouldProcessExpr(span.GetName(), spanKind, attrs, resourceAttrs, durationMs) {
+					filtered++
 					continue
 				}
 
@@ -45,7 +84,14 @@ func (h *Handler) convertOTLPToEnvelopes(orgID string, req *collectortracepb.Exp
 		}
 	}
 
-	return envelopes
+	span.SetAttributes(
+		attribute.Int("gravity.accepted_count", len(envelopes)),
+		attribute.Int("gravity.filtered_count", filtered),
+	)
+	tracing.IngestThroughput.WithLabelValues(orgID).Add(float64(len(envelopes)))
+	tracing.ConversionLatency.Observe(time.Since(start).Seconds())
+
+	return envelopes, rejected
 }
 
 // spanToEnvelope converts a single OTLP span to a Gravity envelope
@@ -338,67 +384,263 @@ func hasPrefix(s string, prefixes ...string) bool {
 	return false
 }
 
-// extractMessages extracts messages from flattened OpenInference attributes
-// Pattern: llm.input_messages.0.message.role, llm.input_messages.0.message.content, etc.
+// messageNode accumulates the flattened attribute fields for a single
+// message index during pass one of extractMessages, before being
+// materialized into a pipeline.Message during pass two.
+type messageNode struct {
+	role, content, name, toolCallID    string
+	functionCallName, functionCallArgs string
+	contents                           map[int]*contentPartNode
+	toolCalls                          map[int]*toolCallNode
+}
+
+type contentPartNode struct {
+	contentType, text, imageURL string
+}
+
+type toolCallNode struct {
+	id, functionName, functionArguments string
+}
+
+// extractMessages extracts messages from flattened OpenInference attributes.
+//
+// This is a two-pass parser: pass one buckets every attribute matching the
+// prefix into a tree of nodes keyed by the integer index at each nesting
+// level (message index, then content-part/tool-call index); pass two walks
+// the tree in index order and materializes pipeline.Message values, leaving
+// gaps in the index sequence as zero-value slots rather than skipping them.
+//
+// Recognized patterns (OpenInference semantic conventions):
+//
+//	llm.input_messages.N.message.{role,content,name,tool_call_id}
+//	llm.input_messages.N.message.{function_call_name,function_call_arguments}
+//	llm.input_messages.N.message.contents.M.message_content.{type,text,image.url}
+//	llm.input_messages.N.message.tool_calls.M.tool_call.{id,function.name,function.arguments}
+//
+// If no flattened keys match the prefix, a JSON-encoded array attached to
+// the bare prefix (e.g. "llm.input_messages") is parsed as a fallback.
 func extractMessages(attrs map[string]string, prefix string) []pipeline.Message {
-	// Map to hold messages by index
-	messageMap := make(map[int]*pipeline.Message)
+	nodes := make(map[int]*messageNode)
+
+	getNode := func(idx int) *messageNode {
+		n, ok := nodes[idx]
+		if !ok {
+			n = &messageNode{}
+			nodes[idx] = n
+		}
+		return n
+	}
 
-	// Scan all attributes for message fields
 	for key, value := range attrs {
 		// Check if this key starts with our prefix (e.g., "llm.input_messages.")
 		if len(key) <= len(prefix) || key[:len(prefix)] != prefix {
 			continue
 		}
 
-		// Parse the index and field name
-		// Format: llm.input_messages.INDEX.message.FIELD
-		remaining := key[len(prefix):] // e.g., "0.message.role"
-
-		var index int
-		var field string
-		n, err := fmt.Sscanf(remaining, "%d.message.%s", &index, &field)
-		if err != nil || n != 2 {
+		segments := strings.Split(key[len(prefix):], ".")
+		if len(segments) < 3 || segments[1] != "message" {
 			continue
 		}
-
-		// Get or create message for this index
-		if messageMap[index] == nil {
-			messageMap[index] = &pipeline.Message{}
+		index, err := strconv.Atoi(segments[0])
+		if err != nil {
+			continue
 		}
+		node := getNode(index)
+		rest := segments[2:]
+
+		switch {
+		case len(rest) == 1:
+			switch rest[0] {
+			case "role":
+				node.role = value
+			case "content":
+				node.content = value
+			case "name":
+				node.name = value
+			case "tool_call_id":
+				node.toolCallID = value
+			case "function_call_name":
+				node.functionCallName = value
+			case "function_call_arguments":
+				node.functionCallArgs = value
+			}
 
-		// Set the appropriate field
-		switch field {
-		case "role":
-			messageMap[index].Role = value
-		case "content":
-			messageMap[index].Content = value
-		case "name":
-			messageMap[index].Name = value
-		case "tool_call_id":
-			messageMap[index].ToolCallID = value
+		case len(rest) >= 3 && rest[0] == "contents":
+			partIndex, err := strconv.Atoi(rest[1])
+			if err != nil || rest[2] != "message_content" {
+				continue
+			}
+			if node.contents == nil {
+				node.contents = make(map[int]*contentPartNode)
+			}
+			part, ok := node.contents[partIndex]
+			if !ok {
+				part = &contentPartNode{}
+				node.contents[partIndex] = part
+			}
+			switch strings.Join(rest[3:], ".") {
+			case "type":
+				part.contentType = value
+			case "text":
+				part.text = value
+			case "image.url":
+				part.imageURL = value
+			}
+
+		case len(rest) >= 3 && rest[0] == "tool_calls":
+			callIndex, err := strconv.Atoi(rest[1])
+			if err != nil || rest[2] != "tool_call" {
+				continue
+			}
+			if node.toolCalls == nil {
+				node.toolCalls = make(map[int]*toolCallNode)
+			}
+			call, ok := node.toolCalls[callIndex]
+			if !ok {
+				call = &toolCallNode{}
+				node.toolCalls[callIndex] = call
+			}
+			switch strings.Join(rest[3:], ".") {
+			case "id":
+				call.id = value
+			case "function.name":
+				call.functionName = value
+			case "function.arguments":
+				call.functionArguments = value
+			}
 		}
 	}
 
-	// Convert map to sorted slice
-	if len(messageMap) == 0 {
-		return nil
+	if len(nodes) == 0 {
+		return extractMessagesFromJSON(attrs, prefix)
 	}
 
-	// Find max index
 	maxIndex := 0
-	for index := range messageMap {
+	for index := range nodes {
 		if index > maxIndex {
 			maxIndex = index
 		}
 	}
 
-	// Build ordered slice
-	messages := make([]pipeline.Message, 0, maxIndex+1)
-	for i := 0; i <= maxIndex; i++ {
-		if msg, ok := messageMap[i]; ok {
-			messages = append(messages, *msg)
+	messages := make([]pipeline.Message, maxIndex+1)
+	for i, node := range nodes {
+		messages[i] = node.materialize()
+	}
+
+	return messages
+}
+
+// materialize converts a messageNode accumulated during pass one into a
+// pipeline.Message, preserving index gaps in contents/tool_calls as
+// zero-value slots rather than skipping them.
+func (n *messageNode) materialize() pipeline.Message {
+	msg := pipeline.Message{
+		Role:                  n.role,
+		Content:               n.content,
+		Name:                  n.name,
+		ToolCallID:            n.toolCallID,
+		FunctionCallName:      n.functionCallName,
+		FunctionCallArguments: n.functionCallArgs,
+	}
+
+	if len(n.contents) > 0 {
+		maxIndex := 0
+		for index := range n.contents {
+			if index > maxIndex {
+				maxIndex = index
+			}
+		}
+		msg.Contents = make([]pipeline.ContentPart, maxIndex+1)
+		for index, part := range n.contents {
+			msg.Contents[index] = pipeline.ContentPart{
+				Type:     part.contentType,
+				Text:     part.text,
+				ImageURL: part.imageURL,
+			}
+		}
+	}
+
+	if len(n.toolCalls) > 0 {
+		maxIndex := 0
+		for index := range n.toolCalls {
+			if index > maxIndex {
+				maxIndex = index
+			}
+		}
+		msg.ToolCalls = make([]pipeline.ToolCall, maxIndex+1)
+		for index, call := range n.toolCalls {
+			msg.ToolCalls[index] = pipeline.ToolCall{
+				ID:                call.id,
+				FunctionName:      call.functionName,
+				FunctionArguments: call.functionArguments,
+			}
+		}
+	}
+
+	return msg
+}
+
+// extractMessagesFromJSON is the fallback path for instrumentations that
+// emit the whole message list as a single JSON-encoded array under the bare
+// attribute key (e.g. "llm.input_messages") instead of flattening it across
+// indexed dotted keys.
+func extractMessagesFromJSON(attrs map[string]string, prefix string) []pipeline.Message {
+	raw, ok := attrs[strings.TrimSuffix(prefix, ".")]
+	if !ok {
+		return nil
+	}
+
+	var rawMessages []struct {
+		Role       string `json:"role"`
+		Content    string `json:"content"`
+		Name       string `json:"name"`
+		ToolCallID string `json:"tool_call_id"`
+		Contents   []struct {
+			Type  string `json:"type"`
+			Text  string `json:"text"`
+			Image struct {
+				URL string `json:"url"`
+			} `json:"image"`
+		} `json:"contents"`
+		ToolCalls []struct {
+			ID       string `json:"id"`
+			Function struct {
+				Name      string `json:"name"`
+				Arguments string `json:"arguments"`
+			} `json:"function"`
+		} `json:"tool_calls"`
+		FunctionCallName      string `json:"function_call_name"`
+		FunctionCallArguments string `json:"function_call_arguments"`
+	}
+	if err := json.Unmarshal([]byte(raw), &rawMessages); err != nil {
+		return nil
+	}
+
+	messages := make([]pipeline.Message, 0, len(rawMessages))
+	for _, rm := range rawMessages {
+		msg := pipeline.Message{
+			Role:                  rm.Role,
+			Content:               rm.Content,
+			Name:                  rm.Name,
+			ToolCallID:            rm.ToolCallID,
+			FunctionCallName:      rm.FunctionCallName,
+			FunctionCallArguments: rm.FunctionCallArguments,
+		}
+		for _, c := range rm.Contents {
+			msg.Contents = append(msg.Contents, pipeline.ContentPart{
+				Type:     c.Type,
+				Text:     c.Text,
+				ImageURL: c.Image.URL,
+			})
+		}
+		for _, tc := range rm.ToolCalls {
+			msg.ToolCalls = append(msg.ToolCalls, pipeline.ToolCall{
+				ID:                tc.ID,
+				FunctionName:      tc.Function.Name,
+				FunctionArguments: tc.Function.Arguments,
+			})
 		}
+		messages = append(messages, msg)
 	}
 
 	return messages
@@ -0,0 +1,151 @@
+package http
+
+import (
+	"testing"
+)
+
+func TestExtractMessages_LiteLLMStreamingTrace(t *testing.T) {
+	// Shaped like a LiteLLM streaming completion span: a single flat user
+	// message and an assistant output message assembled from streamed chunks.
+	attrs := map[string]string{
+		"llm.input_messages.0.message.role":    "system",
+		"llm.input_messages.0.message.content": "You are a helpful assistant.",
+		"llm.input_messages.1.message.role":    "user",
+		"llm.input_messages.1.message.content": "What's the weather in Paris?",
+	}
+
+	messages := extractMessages(attrs, "llm.input_messages.")
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+	if messages[0].Role != "system" || messages[0].Content != "You are a helpful assistant." {
+		t.Errorf("unexpected message[0]: %+v", messages[0])
+	}
+	if messages[1].Role != "user" || messages[1].Content != "What's the weather in Paris?" {
+		t.Errorf("unexpected message[1]: %+v", messages[1])
+	}
+}
+
+func TestExtractMessages_MultimodalContents(t *testing.T) {
+	attrs := map[string]string{
+		"llm.input_messages.0.message.role":                              "user",
+		"llm.input_messages.0.message.contents.0.message_content.type":   "text",
+		"llm.input_messages.0.message.contents.0.message_content.text":   "What's in this image?",
+		"llm.input_messages.0.message.contents.1.message_content.type":   "image",
+		"llm.input_messages.0.message.contents.1.message_content.image.url": "https://example.com/cat.png",
+	}
+
+	messages := extractMessages(attrs, "llm.input_messages.")
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(messages))
+	}
+	if len(messages[0].Contents) != 2 {
+		t.Fatalf("expected 2 content parts, got %d", len(messages[0].Contents))
+	}
+	if messages[0].Contents[0].Type != "text" || messages[0].Contents[0].Text != "What's in this image?" {
+		t.Errorf("unexpected content part 0: %+v", messages[0].Contents[0])
+	}
+	if messages[0].Contents[1].Type != "image" || messages[0].Contents[1].ImageURL != "https://example.com/cat.png" {
+		t.Errorf("unexpected content part 1: %+v", messages[0].Contents[1])
+	}
+}
+
+func TestExtractMessages_OpenAIToolCallingTrace(t *testing.T) {
+	// Shaped like an OpenAI tool-calling span: an assistant message issuing
+	// two tool calls, and a tool result message responding to the first.
+	attrs := map[string]string{
+		"llm.output_messages.0.message.role":                                     "assistant",
+		"llm.output_messages.0.message.tool_calls.0.tool_call.id":                "call_1",
+		"llm.output_messages.0.message.tool_calls.0.tool_call.function.name":      "get_weather",
+		"llm.output_messages.0.message.tool_calls.0.tool_call.function.arguments": `{"city":"Paris"}`,
+		"llm.output_messages.0.message.tool_calls.1.tool_call.id":                "call_2",
+		"llm.output_messages.0.message.tool_calls.1.tool_call.function.name":      "get_time",
+		"llm.output_messages.0.message.tool_calls.1.tool_call.function.arguments": `{"tz":"CET"}`,
+		"llm.output_messages.1.message.role":          "tool",
+		"llm.output_messages.1.message.content":       "18 C, partly cloudy",
+		"llm.output_messages.1.message.tool_call_id":  "call_1",
+	}
+
+	messages := extractMessages(attrs, "llm.output_messages.")
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+
+	assistant := messages[0]
+	if assistant.Role != "assistant" {
+		t.Errorf("expected assistant role, got %q", assistant.Role)
+	}
+	if len(assistant.ToolCalls) != 2 {
+		t.Fatalf("expected 2 tool calls, got %d", len(assistant.ToolCalls))
+	}
+	if assistant.ToolCalls[0].ID != "call_1" || assistant.ToolCalls[0].FunctionName != "get_weather" || assistant.ToolCalls[0].FunctionArguments != `{"city":"Paris"}` {
+		t.Errorf("unexpected tool call 0: %+v", assistant.ToolCalls[0])
+	}
+	if assistant.ToolCalls[1].ID != "call_2" || assistant.ToolCalls[1].FunctionName != "get_time" {
+		t.Errorf("unexpected tool call 1: %+v", assistant.ToolCalls[1])
+	}
+
+	toolResult := messages[1]
+	if toolResult.Role != "tool" || toolResult.ToolCallID != "call_1" || toolResult.Content != "18 C, partly cloudy" {
+		t.Errorf("unexpected tool result message: %+v", toolResult)
+	}
+}
+
+func TestExtractMessages_LegacyFunctionCall(t *testing.T) {
+	attrs := map[string]string{
+		"llm.output_messages.0.message.role":                     "assistant",
+		"llm.output_messages.0.message.function_call_name":       "get_weather",
+		"llm.output_messages.0.message.function_call_arguments":  `{"city":"Paris"}`,
+	}
+
+	messages := extractMessages(attrs, "llm.output_messages.")
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(messages))
+	}
+	if messages[0].FunctionCallName != "get_weather" || messages[0].FunctionCallArguments != `{"city":"Paris"}` {
+		t.Errorf("unexpected message: %+v", messages[0])
+	}
+}
+
+func TestExtractMessages_IndexGapsPreserved(t *testing.T) {
+	attrs := map[string]string{
+		"llm.input_messages.0.message.role": "system",
+		// index 1 deliberately missing
+		"llm.input_messages.2.message.role": "user",
+	}
+
+	messages := extractMessages(attrs, "llm.input_messages.")
+	if len(messages) != 3 {
+		t.Fatalf("expected 3 messages (gap preserved), got %d", len(messages))
+	}
+	if messages[1].Role != "" {
+		t.Errorf("expected empty slot at index 1, got %+v", messages[1])
+	}
+	if messages[2].Role != "user" {
+		t.Errorf("expected user role at index 2, got %+v", messages[2])
+	}
+}
+
+func TestExtractMessages_JSONFallback(t *testing.T) {
+	attrs := map[string]string{
+		"llm.input_messages": `[{"role":"user","content":"hi","tool_calls":[{"id":"call_1","function":{"name":"f","arguments":"{}"}}]}]`,
+	}
+
+	messages := extractMessages(attrs, "llm.input_messages.")
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 message from JSON fallback, got %d", len(messages))
+	}
+	if messages[0].Role != "user" || messages[0].Content != "hi" {
+		t.Errorf("unexpected message: %+v", messages[0])
+	}
+	if len(messages[0].ToolCalls) != 1 || messages[0].ToolCalls[0].FunctionName != "f" {
+		t.Errorf("unexpected tool calls: %+v", messages[0].ToolCalls)
+	}
+}
+
+func TestExtractMessages_NoMatch(t *testing.T) {
+	attrs := map[string]string{"unrelated.key": "value"}
+	if messages := extractMessages(attrs, "llm.input_messages."); messages != nil {
+		t.Errorf("expected nil messages, got %+v", messages)
+	}
+}
@@ -9,14 +9,19 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/youware/gravity/internal/ingest/auth"
 	"github.com/youware/gravity/internal/shared/config"
+	"github.com/youware/gravity/internal/shared/tracing"
 )
 
 // Server represents the HTTP server
 type Server struct {
-	httpServer *http.Server
-	config     *config.Config
-	handler    *Handler
+	httpServer  *http.Server
+	grpcServer  *GRPCServer
+	adminServer *tracing.AdminServer
+	config      *config.Config
+	handler     *Handler
+	keyStore    auth.KeyStore // non-nil when cfg.Auth.Enabled
 }
 
 // NewServer creates a new HTTP server instance
@@ -33,6 +38,7 @@ func NewServer(cfg *config.Config) (*Server, error) {
 	router.Use(middleware.RequestID)
 	router.Use(middleware.RealIP)
 	router.Use(requestLogger(slog.Default()))
+	router.Use(tracing.HTTPMiddleware)
 	router.Use(middleware.Recoverer)
 	router.Use(middleware.Timeout(60 * time.Second))
 
@@ -51,9 +57,43 @@ func NewServer(cfg *config.Config) (*Server, error) {
 		w.Write([]byte("READY"))
 	})
 
-	// OTLP endpoints
-	router.Post("/v1/traces", handler.HandleTraces)
-	router.Post("/v1/metrics", handler.HandleMetrics)
+	bodyMiddleware := BodyMiddleware(cfg.HTTP.MaxBodyBytes)
+	traces := bodyMiddleware(handler.HandleTraces)
+	metrics := bodyMiddleware(handler.HandleMetrics)
+
+	srv := &Server{
+		config:  cfg,
+		handler: handler,
+	}
+
+	var authMiddleware *auth.Middleware
+	if cfg.Auth.Enabled {
+		keyStore, err := auth.NewBoltKeyStore(cfg.Auth.DBPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open access-key store: %w", err)
+		}
+		srv.keyStore = keyStore
+
+		authMiddleware = auth.NewMiddleware(keyStore)
+		traces = authMiddleware.Wrap(traces)
+		metrics = authMiddleware.Wrap(metrics)
+	}
+
+	// OTLP endpoints; BodyMiddleware transparently handles
+	// gzip/zstd-encoded bodies before the handler decodes protobuf or JSON,
+	// and (when auth is enabled) the access-key middleware resolves org_id
+	// before either handler runs.
+	router.Post("/v1/traces", traces)
+	router.Post("/v1/metrics", metrics)
+
+	// Admin endpoints; gated behind the same access-key auth as the OTLP
+	// endpoints when enabled, since it lets a caller rewrite the CEL filter
+	// rules for every org.
+	filterReload := handler.HandleFilterReload
+	if authMiddleware != nil {
+		filterReload = authMiddleware.Wrap(filterReload)
+	}
+	router.Put("/admin/filter", filterReload)
 
 	httpServer := &http.Server{
 		Addr:         cfg.HTTP.Address,
@@ -61,21 +101,71 @@ func NewServer(cfg *config.Config) (*Server, error) {
 		ReadTimeout:  cfg.HTTP.ReadTimeout,
 		WriteTimeout: cfg.HTTP.WriteTimeout,
 	}
+	srv.httpServer = httpServer
 
-	return &Server{
-		httpServer: httpServer,
-		config:     cfg,
-		handler:    handler,
-	}, nil
+	if cfg.GRPC.Enabled {
+		g, err := newGRPCServer(cfg.GRPC.Address, handler, authMiddleware)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gRPC server: %w", err)
+		}
+		srv.grpcServer = g
+	}
+
+	if cfg.Admin.Enabled {
+		srv.adminServer = tracing.NewAdminServer(cfg.Admin.Address)
+	}
+
+	return srv, nil
 }
 
-// Start starts the HTTP server
+// Start starts the HTTP server, and the OTLP gRPC and admin/metrics servers
+// alongside it if enabled.
 func (s *Server) Start() error {
+	if s.grpcServer != nil {
+		go func() {
+			slog.Info("starting OTLP gRPC server", "address", s.config.GRPC.Address)
+			if err := s.grpcServer.Serve(); err != nil {
+				slog.Error("gRPC server error", "error", err)
+			}
+		}()
+	}
+
+	if s.adminServer != nil {
+		go func() {
+			slog.Info("starting admin/metrics server", "address", s.config.Admin.Address)
+			if err := s.adminServer.Start(); err != nil {
+				slog.Error("admin server error", "error", err)
+			}
+		}()
+	}
+
 	return s.httpServer.ListenAndServe()
 }
 
-// Shutdown gracefully shuts down the server
+// ReloadFilter recompiles the span filter's CEL expressions in place,
+// intended to be called from a SIGHUP handler.
+func (s *Server) ReloadFilter(expressions []string) error {
+	return s.handler.spanFilter.Reload(expressions)
+}
+
+// Shutdown gracefully shuts down the HTTP server and the gRPC/admin servers, if running.
 func (s *Server) Shutdown(ctx context.Context) error {
+	if s.grpcServer != nil {
+		s.grpcServer.Stop()
+	}
+
+	if s.adminServer != nil {
+		if err := s.adminServer.Shutdown(ctx); err != nil {
+			slog.Error("admin server shutdown error", "error", err)
+		}
+	}
+
+	if s.keyStore != nil {
+		if err := s.keyStore.Close(); err != nil {
+			slog.Error("access-key store shutdown error", "error", err)
+		}
+	}
+
 	return s.httpServer.Shutdown(ctx)
 }
 
@@ -95,13 +185,18 @@ func corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// requestLogger logs one structured record per request, including the
+// org_id and span/metric counts handlers record into the request's
+// requestMetrics — this replaces handlers logging their own ad-hoc summary
+// lines for access-log purposes.
 func requestLogger(logger *slog.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
 			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
 
-			next.ServeHTTP(ww, r)
+			ctx, metrics := withRequestMetrics(r.Context())
+			next.ServeHTTP(ww, r.WithContext(ctx))
 
 			status := ww.Status()
 			if status == 0 {
@@ -120,6 +215,16 @@ func requestLogger(logger *slog.Logger) func(http.Handler) http.Handler {
 				args = append(args, "request_id", reqID)
 			}
 
+			if metrics.OrgID != "" {
+				args = append(args, "org_id", metrics.OrgID, "decompressed_size", metrics.DecompressedSize)
+			}
+			if metrics.SpanCount > 0 {
+				args = append(args, "span_count", metrics.SpanCount)
+			}
+			if metrics.MetricCount > 0 {
+				args = append(args, "metric_count", metrics.MetricCount)
+			}
+
 			logger.Info("request completed", args...)
 		})
 	}
@@ -87,8 +87,38 @@ type Message struct {
 	Content string `json:"content,omitempty"` // message.content: text content of the message
 	Name    string `json:"name,omitempty"`    // message.name: for tool messages, the tool name
 
+	// Contents holds multimodal content parts, for messages that carry more
+	// than a single flat text blob. Maps to message.contents.N.message_content.
+	Contents []ContentPart `json:"contents,omitempty"`
+
 	// Tool call fields (for assistant messages that invoke tools)
 	ToolCallID string `json:"tool_call_id,omitempty"` // message.tool_call_id: links tool result to tool call
+
+	// ToolCalls holds the tool/function calls an assistant message requested.
+	// Maps to message.tool_calls.N.tool_call.
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+
+	// Legacy OpenAI single function_call fields (pre-dates the tool_calls list).
+	FunctionCallName      string `json:"function_call_name,omitempty"`
+	FunctionCallArguments string `json:"function_call_arguments,omitempty"`
+}
+
+// ContentPart represents a single part of a multimodal message, e.g. a text
+// span or an image. Maps to message.contents.N.message_content from the
+// OpenInference spec.
+type ContentPart struct {
+	Type     string `json:"type"`                // message_content.type: "text", "image", etc.
+	Text     string `json:"text,omitempty"`       // message_content.text
+	ImageURL string `json:"image_url,omitempty"` // message_content.image.url
+}
+
+// ToolCall represents a single tool/function invocation requested by an
+// assistant message. Maps to message.tool_calls.N.tool_call from the
+// OpenInference spec.
+type ToolCall struct {
+	ID                string `json:"id,omitempty"`        // tool_call.id
+	FunctionName      string `json:"function_name,omitempty"`
+	FunctionArguments string `json:"function_arguments,omitempty"` // JSON-encoded arguments
 }
 
 // Tool represents an available tool/function that can be called by the LLM
@@ -0,0 +1,128 @@
+// Package reproducer captures failing (or sampled) ingest requests as
+// self-describing artifacts so they can be replayed against a dev endpoint
+// to reproduce production 400/500s deterministically.
+package reproducer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// Artifact is everything needed to replay a single ingest request.
+type Artifact struct {
+	RequestID  string              `json:"request_id"`
+	Method     string              `json:"method"`
+	Path       string              `json:"path"`
+	Headers    map[string][]string `json:"headers"`
+	Trailers   map[string][]string `json:"trailers,omitempty"`
+	RemoteAddr string              `json:"remote_addr"`
+	OrgID      string              `json:"org_id"`
+	Reason     string              `json:"reason"` // why this request was captured
+	CapturedAt time.Time           `json:"captured_at"`
+}
+
+// Sink persists a captured artifact's metadata and raw body bytes as a
+// self-describing pair: "{request_id}.json" and "{request_id}.bin".
+type Sink interface {
+	Save(ctx context.Context, artifact Artifact, body []byte) error
+}
+
+// Config controls when the HTTP handlers capture a request.
+type Config struct {
+	Enabled bool
+	// SampleRate captures this fraction of successful requests in addition
+	// to every request that hits an unmarshal/pipeline error. 0 disables
+	// sampling; 1 captures everything.
+	SampleRate float64
+}
+
+// Capturer decides whether to capture a request and persists it via Sink.
+type Capturer struct {
+	cfg  Config
+	sink Sink
+}
+
+// New creates a Capturer. sink may be nil only if cfg.Enabled is false.
+func New(cfg Config, sink Sink) *Capturer {
+	return &Capturer{cfg: cfg, sink: sink}
+}
+
+// ShouldCapture reports whether a request should be captured: always when
+// hadErr is true (unmarshal/pipeline failure), otherwise per SampleRate.
+func (c *Capturer) ShouldCapture(hadErr bool) bool {
+	if !c.cfg.Enabled {
+		return false
+	}
+	if hadErr {
+		return true
+	}
+	return c.cfg.SampleRate > 0 && rand.Float64() < c.cfg.SampleRate
+}
+
+// redactedHeaders lists header names (canonical form) that carry credentials
+// and must never reach a persisted Artifact: Capturer captures real
+// production requests, so writing these verbatim would leak live,
+// unexpired API credentials to anyone who can read the capture
+// directory/bucket.
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+const redactedValue = "[REDACTED]"
+
+// redactHeaders returns a copy of h with any redactedHeaders values replaced,
+// leaving h itself untouched since it may still be read by the rest of the
+// request pipeline after Capture returns.
+func redactHeaders(h http.Header) map[string][]string {
+	redacted := make(map[string][]string, len(h))
+	for name, values := range h {
+		redacted[name] = values
+	}
+	for _, name := range redactedHeaders {
+		if _, ok := redacted[name]; ok {
+			redacted[name] = []string{redactedValue}
+		}
+	}
+	return redacted
+}
+
+// Capture persists r's metadata and body as an artifact, tagging it with
+// reason (e.g. "decode_error", "sampled"). Authorization and other
+// credential-bearing headers are redacted before being written, since
+// artifacts are replay material that may sit in shared storage. Errors are
+// returned rather than swallowed so callers can decide whether a capture
+// failure is worth logging.
+func (c *Capturer) Capture(ctx context.Context, r *http.Request, body []byte, orgID, requestID, reason string) error {
+	artifact := Artifact{
+		RequestID:  requestID,
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		Headers:    redactHeaders(r.Header),
+		Trailers:   map[string][]string(r.Trailer),
+		RemoteAddr: r.RemoteAddr,
+		OrgID:      orgID,
+		Reason:     reason,
+		CapturedAt: time.Now(),
+	}
+	return c.sink.Save(ctx, artifact, body)
+}
+
+// Load reads back an artifact previously written by a Sink that stores
+// "{request_id}.json"/"{request_id}.bin" pairs readable via r.
+func Load(r io.Reader, body io.Reader) (*Artifact, []byte, error) {
+	var artifact Artifact
+	if err := json.NewDecoder(r).Decode(&artifact); err != nil {
+		return nil, nil, fmt.Errorf("reproducer: failed to decode artifact metadata: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(body); err != nil {
+		return nil, nil, fmt.Errorf("reproducer: failed to read artifact body: %w", err)
+	}
+
+	return &artifact, buf.Bytes(), nil
+}
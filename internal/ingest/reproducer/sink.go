@@ -0,0 +1,74 @@
+package reproducer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/youware/gravity/internal/storage"
+)
+
+// LocalSink writes captured artifacts under a local directory, for
+// single-node deployments or local dev.
+type LocalSink struct {
+	dir string
+}
+
+// NewLocalSink creates a LocalSink rooted at dir, creating it if necessary.
+func NewLocalSink(dir string) (*LocalSink, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("reproducer: failed to create capture dir %s: %w", dir, err)
+	}
+	return &LocalSink{dir: dir}, nil
+}
+
+// Save implements Sink.
+func (s *LocalSink) Save(ctx context.Context, artifact Artifact, body []byte) error {
+	meta, err := json.MarshalIndent(artifact, "", "  ")
+	if err != nil {
+		return fmt.Errorf("reproducer: failed to marshal artifact: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(s.dir, artifact.RequestID+".json"), meta, 0644); err != nil {
+		return fmt.Errorf("reproducer: failed to write artifact metadata: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.dir, artifact.RequestID+".bin"), body, 0644); err != nil {
+		return fmt.Errorf("reproducer: failed to write artifact body: %w", err)
+	}
+	return nil
+}
+
+// BucketSink writes captured artifacts to a storage.Bucket, for
+// deployments that want captures centralized rather than left on a pod's
+// local disk.
+type BucketSink struct {
+	bucket storage.Bucket
+	prefix string
+}
+
+// NewBucketSink creates a BucketSink writing under prefix (e.g. "captures/").
+func NewBucketSink(bucket storage.Bucket, prefix string) *BucketSink {
+	return &BucketSink{bucket: bucket, prefix: prefix}
+}
+
+// Save implements Sink.
+func (s *BucketSink) Save(ctx context.Context, artifact Artifact, body []byte) error {
+	meta, err := json.MarshalIndent(artifact, "", "  ")
+	if err != nil {
+		return fmt.Errorf("reproducer: failed to marshal artifact: %w", err)
+	}
+
+	metaKey := s.prefix + artifact.RequestID + ".json"
+	if err := s.bucket.Put(ctx, metaKey, "application/json", bytes.NewReader(meta), int64(len(meta))); err != nil {
+		return fmt.Errorf("reproducer: failed to store artifact metadata: %w", err)
+	}
+
+	bodyKey := s.prefix + artifact.RequestID + ".bin"
+	if err := s.bucket.Put(ctx, bodyKey, "application/octet-stream", bytes.NewReader(body), int64(len(body))); err != nil {
+		return fmt.Errorf("reproducer: failed to store artifact body: %w", err)
+	}
+	return nil
+}
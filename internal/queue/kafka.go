@@ -0,0 +1,193 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	kafka "github.com/segmentio/kafka-go"
+
+	"github.com/youware/gravity/internal/ingest/pipeline"
+)
+
+// kafkaProducer publishes envelope batches to a Kafka topic using
+// segmentio/kafka-go, with idempotent keys derived from TraceID+SpanID so
+// reprocessing the same span is a no-op for key-aware consumers.
+type kafkaProducer struct {
+	writer *kafka.Writer
+	cfg    Config
+}
+
+func newKafkaProducer(cfg Config) (*kafkaProducer, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, fmt.Errorf("queue: kafka producer requires at least one broker")
+	}
+
+	batchTimeout := cfg.BatchTimeout
+	if batchTimeout == 0 {
+		batchTimeout = 100 * time.Millisecond
+	}
+	batchSize := cfg.BatchSize
+	if batchSize == 0 {
+		batchSize = 100
+	}
+
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(cfg.Brokers...),
+		Topic:        cfg.Topic,
+		Balancer:     &kafka.Hash{},
+		BatchSize:    batchSize,
+		BatchTimeout: batchTimeout,
+		RequiredAcks: kafka.RequireOne,
+	}
+
+	return &kafkaProducer{writer: writer, cfg: cfg}, nil
+}
+
+// Publish sends a batch of envelopes to the queue, retrying with exponential
+// backoff up to cfg.MaxRetries times.
+func (p *kafkaProducer) Publish(ctx context.Context, batch *pipeline.Batch) error {
+	messages := make([]kafka.Message, 0, len(batch.Envelopes))
+	for _, envelope := range batch.Envelopes {
+		value, err := json.Marshal(envelope)
+		if err != nil {
+			return fmt.Errorf("queue: failed to marshal envelope: %w", err)
+		}
+
+		messages = append(messages, kafka.Message{
+			Key:   []byte(idempotentKey(envelope)),
+			Value: value,
+		})
+	}
+
+	if len(messages) == 0 {
+		return nil
+	}
+
+	var lastErr error
+	backoff := p.cfg.RetryBackoff
+	if backoff == 0 {
+		backoff = 100 * time.Millisecond
+	}
+
+	for attempt := 0; attempt <= p.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			slog.Warn("retrying kafka publish", "attempt", attempt, "error", lastErr)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff * time.Duration(1<<uint(attempt-1))):
+			}
+		}
+
+		if err := p.writer.WriteMessages(ctx, messages...); err != nil {
+			lastErr = err
+			continue
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("queue: kafka publish failed after %d attempts: %w", p.cfg.MaxRetries+1, lastErr)
+}
+
+// Close gracefully shuts down the producer.
+func (p *kafkaProducer) Close() error {
+	return p.writer.Close()
+}
+
+// kafkaConsumer consumes envelopes from a Kafka topic using a consumer
+// group, forwarding repeatedly-failing envelopes to a dead-letter topic.
+type kafkaConsumer struct {
+	reader *kafka.Reader
+	dlq    *kafka.Writer
+	cfg    Config
+}
+
+func newKafkaConsumer(cfg Config) (*kafkaConsumer, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, fmt.Errorf("queue: kafka consumer requires at least one broker")
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: cfg.Brokers,
+		Topic:   cfg.Topic,
+		GroupID: cfg.ConsumerGroup,
+	})
+
+	dlq := &kafka.Writer{
+		Addr:     kafka.TCP(cfg.Brokers...),
+		Topic:    cfg.deadLetterTopic(),
+		Balancer: &kafka.Hash{},
+	}
+
+	return &kafkaConsumer{reader: reader, dlq: dlq, cfg: cfg}, nil
+}
+
+// Consume starts consuming messages from the queue, invoking handler for
+// each envelope and routing envelopes that fail MaxRetries times to the
+// dead-letter topic.
+func (c *kafkaConsumer) Consume(ctx context.Context, handler func(envelope pipeline.Envelope) error) error {
+	for {
+		msg, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("queue: failed to fetch kafka message: %w", err)
+		}
+
+		var envelope pipeline.Envelope
+		if err := json.Unmarshal(msg.Value, &envelope); err != nil {
+			slog.Error("queue: failed to unmarshal kafka message", "error", err)
+			if commitErr := c.reader.CommitMessages(ctx, msg); commitErr != nil {
+				return fmt.Errorf("queue: failed to commit message: %w", commitErr)
+			}
+			continue
+		}
+
+		if err := c.processWithRetries(ctx, envelope, msg.Value, handler); err != nil {
+			slog.Error("queue: dropping envelope after retries exhausted", "error", err)
+		}
+
+		if err := c.reader.CommitMessages(ctx, msg); err != nil {
+			return fmt.Errorf("queue: failed to commit message: %w", err)
+		}
+	}
+}
+
+func (c *kafkaConsumer) processWithRetries(ctx context.Context, envelope pipeline.Envelope, raw []byte, handler func(pipeline.Envelope) error) error {
+	var lastErr error
+	for attempt := 0; attempt <= c.cfg.MaxRetries; attempt++ {
+		if err := handler(envelope); err != nil {
+			lastErr = err
+			continue
+		}
+		return nil
+	}
+
+	if err := c.dlq.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(idempotentKey(envelope)),
+		Value: raw,
+	}); err != nil {
+		return fmt.Errorf("failed to write to dead-letter topic after handler error %v: %w", lastErr, err)
+	}
+
+	return lastErr
+}
+
+// Close gracefully shuts down the consumer and its dead-letter producer.
+func (c *kafkaConsumer) Close() error {
+	dlqErr := c.dlq.Close()
+	if err := c.reader.Close(); err != nil {
+		return err
+	}
+	return dlqErr
+}
+
+// idempotentKey derives a stable partition/dedup key for an envelope.
+func idempotentKey(envelope pipeline.Envelope) string {
+	return envelope.TraceID + ":" + envelope.SpanID
+}
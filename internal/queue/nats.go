@@ -0,0 +1,190 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/youware/gravity/internal/ingest/pipeline"
+)
+
+// natsProducer publishes envelope batches to a NATS JetStream stream. Each
+// envelope is published with a Nats-Msg-Id header derived from
+// TraceID+SpanID, which JetStream uses for its own duplicate-message window
+// to make retried publishes idempotent.
+type natsProducer struct {
+	nc  *nats.Conn
+	js  jetstream.JetStream
+	cfg Config
+}
+
+func newNATSProducer(cfg Config) (*natsProducer, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, fmt.Errorf("queue: nats producer requires at least one broker URL")
+	}
+
+	nc, err := nats.Connect(strings.Join(cfg.Brokers, ","))
+	if err != nil {
+		return nil, fmt.Errorf("queue: failed to connect to nats: %w", err)
+	}
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("queue: failed to create jetstream context: %w", err)
+	}
+
+	return &natsProducer{nc: nc, js: js, cfg: cfg}, nil
+}
+
+// Publish sends a batch of envelopes to the queue, retrying with exponential
+// backoff up to cfg.MaxRetries times.
+func (p *natsProducer) Publish(ctx context.Context, batch *pipeline.Batch) error {
+	backoff := p.cfg.RetryBackoff
+	if backoff == 0 {
+		backoff = 100 * time.Millisecond
+	}
+
+	for _, envelope := range batch.Envelopes {
+		value, err := json.Marshal(envelope)
+		if err != nil {
+			return fmt.Errorf("queue: failed to marshal envelope: %w", err)
+		}
+
+		msg := &nats.Msg{
+			Subject: p.cfg.Topic,
+			Data:    value,
+			Header:  nats.Header{},
+		}
+		msg.Header.Set(jetstream.MsgIDHeader, idempotentKey(envelope))
+
+		var lastErr error
+		for attempt := 0; attempt <= p.cfg.MaxRetries; attempt++ {
+			if attempt > 0 {
+				slog.Warn("retrying nats publish", "attempt", attempt, "error", lastErr)
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(backoff * time.Duration(1<<uint(attempt-1))):
+				}
+			}
+
+			if _, err := p.js.PublishMsg(ctx, msg); err != nil {
+				lastErr = err
+				continue
+			}
+
+			lastErr = nil
+			break
+		}
+
+		if lastErr != nil {
+			return fmt.Errorf("queue: nats publish failed after %d attempts: %w", p.cfg.MaxRetries+1, lastErr)
+		}
+	}
+
+	return nil
+}
+
+// Close gracefully shuts down the producer's NATS connection.
+func (p *natsProducer) Close() error {
+	p.nc.Close()
+	return nil
+}
+
+// natsConsumer consumes envelopes from a durable JetStream consumer,
+// forwarding repeatedly-failing envelopes to a dead-letter subject.
+type natsConsumer struct {
+	nc  *nats.Conn
+	js  jetstream.JetStream
+	cfg Config
+}
+
+func newNATSConsumer(cfg Config) (*natsConsumer, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, fmt.Errorf("queue: nats consumer requires at least one broker URL")
+	}
+
+	nc, err := nats.Connect(strings.Join(cfg.Brokers, ","))
+	if err != nil {
+		return nil, fmt.Errorf("queue: failed to connect to nats: %w", err)
+	}
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("queue: failed to create jetstream context: %w", err)
+	}
+
+	return &natsConsumer{nc: nc, js: js, cfg: cfg}, nil
+}
+
+// Consume starts consuming messages from a durable JetStream consumer bound
+// to cfg.Topic, invoking handler for each envelope.
+func (c *natsConsumer) Consume(ctx context.Context, handler func(envelope pipeline.Envelope) error) error {
+	stream, err := c.js.Stream(ctx, c.cfg.Topic)
+	if err != nil {
+		return fmt.Errorf("queue: failed to bind to stream %q: %w", c.cfg.Topic, err)
+	}
+
+	consumer, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:       c.cfg.ConsumerGroup,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		FilterSubject: c.cfg.Topic,
+	})
+	if err != nil {
+		return fmt.Errorf("queue: failed to create durable consumer %q: %w", c.cfg.ConsumerGroup, err)
+	}
+
+	consumeCtx, err := consumer.Consume(func(msg jetstream.Msg) {
+		var envelope pipeline.Envelope
+		if err := json.Unmarshal(msg.Data(), &envelope); err != nil {
+			slog.Error("queue: failed to unmarshal nats message", "error", err)
+			_ = msg.Ack()
+			return
+		}
+
+		if err := c.processWithRetries(ctx, envelope, msg.Data(), handler); err != nil {
+			slog.Error("queue: dropping envelope after retries exhausted", "error", err)
+		}
+
+		_ = msg.Ack()
+	})
+	if err != nil {
+		return fmt.Errorf("queue: failed to start consuming: %w", err)
+	}
+	defer consumeCtx.Stop()
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (c *natsConsumer) processWithRetries(ctx context.Context, envelope pipeline.Envelope, raw []byte, handler func(pipeline.Envelope) error) error {
+	var lastErr error
+	for attempt := 0; attempt <= c.cfg.MaxRetries; attempt++ {
+		if err := handler(envelope); err != nil {
+			lastErr = err
+			continue
+		}
+		return nil
+	}
+
+	dlqSubject := c.cfg.deadLetterTopic()
+	if err := c.nc.Publish(dlqSubject, raw); err != nil {
+		return fmt.Errorf("failed to publish to dead-letter subject %q after handler error %v: %w", dlqSubject, lastErr, err)
+	}
+
+	return lastErr
+}
+
+// Close gracefully shuts down the consumer's NATS connection.
+func (c *natsConsumer) Close() error {
+	c.nc.Close()
+	return nil
+}
@@ -2,6 +2,8 @@ package queue
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/youware/gravity/internal/ingest/pipeline"
 )
@@ -26,8 +28,54 @@ type Consumer interface {
 
 // Config holds queue-specific configuration
 type Config struct {
-	Type       string
+	Type       string // "kafka" or "nats" (JetStream)
 	Brokers    []string
 	Topic      string
 	MaxRetries int
+
+	// ConsumerGroup is the Kafka consumer group / NATS durable consumer name.
+	ConsumerGroup string
+
+	// DeadLetterTopic receives envelopes that repeatedly fail the handler
+	// after MaxRetries attempts. Defaults to Topic+".dlq" if empty.
+	DeadLetterTopic string
+
+	// Batch publish tuning
+	BatchSize    int
+	BatchTimeout time.Duration // linger duration before flushing a partial batch
+
+	// RetryBackoff is the base delay for exponential backoff between publish retries.
+	RetryBackoff time.Duration
+}
+
+// deadLetterTopic returns the configured DLQ topic, defaulting to Topic+".dlq".
+func (c Config) deadLetterTopic() string {
+	if c.DeadLetterTopic != "" {
+		return c.DeadLetterTopic
+	}
+	return c.Topic + ".dlq"
+}
+
+// NewProducer creates a Producer for the backend selected by cfg.Type.
+func NewProducer(cfg Config) (Producer, error) {
+	switch cfg.Type {
+	case "kafka":
+		return newKafkaProducer(cfg)
+	case "nats", "jetstream":
+		return newNATSProducer(cfg)
+	default:
+		return nil, fmt.Errorf("queue: unsupported producer type %q", cfg.Type)
+	}
+}
+
+// NewConsumer creates a Consumer for the backend selected by cfg.Type.
+func NewConsumer(cfg Config) (Consumer, error) {
+	switch cfg.Type {
+	case "kafka":
+		return newKafkaConsumer(cfg)
+	case "nats", "jetstream":
+		return newNATSConsumer(cfg)
+	default:
+		return nil, fmt.Errorf("queue: unsupported consumer type %q", cfg.Type)
+	}
 }
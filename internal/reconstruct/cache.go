@@ -0,0 +1,64 @@
+package reconstruct
+
+import (
+	"container/list"
+	"sync"
+)
+
+// blobCache is a fixed-capacity in-memory LRU cache of decompressed blob
+// content keyed by hash, shared across ReadAll/ReadRange calls so
+// reconstructing related traces (which often share chunks) doesn't refetch
+// and redecompress the same blob repeatedly.
+type blobCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type blobCacheEntry struct {
+	hash    string
+	content string
+}
+
+func newBlobCache(capacity int) *blobCache {
+	return &blobCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element, capacity),
+	}
+}
+
+func (c *blobCache) get(hash string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[hash]
+	if !ok {
+		return "", false
+	}
+	c.ll.MoveToFront(el)
+	return el.Value.(*blobCacheEntry).content, true
+}
+
+func (c *blobCache) put(hash, content string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[hash]; ok {
+		c.ll.MoveToFront(el)
+		el.Value.(*blobCacheEntry).content = content
+		return
+	}
+
+	el := c.ll.PushFront(&blobCacheEntry{hash: hash, content: content})
+	c.items[hash] = el
+
+	if c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		if oldest != nil {
+			c.ll.Remove(oldest)
+			delete(c.items, oldest.Value.(*blobCacheEntry).hash)
+		}
+	}
+}
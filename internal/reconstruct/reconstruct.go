@@ -0,0 +1,474 @@
+// Package reconstruct downloads the chunks making up a stored trace and
+// reassembles them, fetching blobs concurrently and caching decompressed
+// content across calls. It backs both the reconstruct CLI and any service
+// that wants to stream a slice of a trace without materializing the whole
+// thing.
+package reconstruct
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/youware/gravity/internal/blobcodec"
+	"github.com/youware/gravity/internal/storage"
+	"github.com/youware/gravity/internal/worker/dict"
+	"github.com/youware/gravity/internal/worker/pack"
+)
+
+// ChunkIndex represents the index mapping trace_id to content hashes.
+// Sizes and Offsets are populated for indexes written after range-read
+// support was added; older indexes omit them (they decode as nil slices),
+// and ReadRange falls back to reconstructing the full trace and slicing it
+// in memory since there's no way to know which chunks to skip.
+// PackIDs/PackOffsets/PackLengths are populated instead of a plain blob
+// lookup when the chunk was written through a pack.Store
+// (cfg.PackingEnabled); indexes written without pack storage omit them. See
+// worker.ChunkIndex, which this mirrors field-for-field. DictID records
+// which trained dictionary (if any) the span's chunks were compressed
+// against, mirroring worker.ChunkIndex.DictID.
+type ChunkIndex struct {
+	TraceID     string   `json:"trace_id"`
+	SpanID      string   `json:"span_id"`
+	Hashes      []string `json:"hashes"`
+	Sizes       []int64  `json:"sizes,omitempty"`        // decompressed size of each chunk, parallel to Hashes
+	Offsets     []int64  `json:"offsets,omitempty"`      // cumulative start offset of each chunk in the reconstructed content
+	PackIDs     []string `json:"pack_ids,omitempty"`     // pack object each chunk was written to, parallel to Hashes
+	PackOffsets []int64  `json:"pack_offsets,omitempty"` // byte offset of each chunk within its pack
+	PackLengths []int64  `json:"pack_lengths,omitempty"` // byte length of each chunk within its pack
+	DictID      string   `json:"dict_id,omitempty"`      // dictionary version the span's chunks were compressed with, empty if none
+}
+
+// hasOffsets reports whether idx carries per-chunk size/offset metadata.
+func (idx *ChunkIndex) hasOffsets() bool {
+	return len(idx.Sizes) == len(idx.Hashes) && len(idx.Offsets) == len(idx.Hashes)
+}
+
+// hasPackLocations reports whether idx carries per-chunk pack placement,
+// i.e. its chunks live in shared pack objects rather than standalone blobs.
+func (idx *ChunkIndex) hasPackLocations() bool {
+	return len(idx.PackIDs) == len(idx.Hashes) && len(idx.PackOffsets) == len(idx.Hashes) && len(idx.PackLengths) == len(idx.Hashes)
+}
+
+// chunkRef locates one chunk's blob: either a standalone object (packID
+// empty, resolved via Client.blobKey) or a byte range within a shared pack
+// object.
+type chunkRef struct {
+	hash       string
+	packID     string
+	packOffset int64
+	packLength int64
+}
+
+// refAt builds the chunkRef for idx.Hashes[i], including its pack location
+// if idx.hasPackLocations().
+func (idx *ChunkIndex) refAt(i int) chunkRef {
+	ref := chunkRef{hash: idx.Hashes[i]}
+	if idx.hasPackLocations() {
+		ref.packID = idx.PackIDs[i]
+		ref.packOffset = idx.PackOffsets[i]
+		ref.packLength = idx.PackLengths[i]
+	}
+	return ref
+}
+
+// allRefs returns a chunkRef for every hash in idx, in order.
+func (idx *ChunkIndex) allRefs() []chunkRef {
+	refs := make([]chunkRef, len(idx.Hashes))
+	for i := range idx.Hashes {
+		refs[i] = idx.refAt(i)
+	}
+	return refs
+}
+
+// Client reconstructs trace content from a storage.Bucket, caching
+// decompressed blobs across calls and fetching chunks concurrently with
+// bounded parallelism and per-chunk retries.
+type Client struct {
+	bucket      storage.Bucket
+	indexesPath string
+	blobsPath   string
+	codec       blobcodec.Codec
+	org         string
+	orgScoped   bool
+
+	dictStore  *dict.Store
+	dictsMu    sync.Mutex
+	dictsCache map[string][]byte // "org/version" -> dictionary bytes
+
+	packStore *pack.Store
+
+	maxConcurrency int
+	maxRetries     int
+	retryBackoff   time.Duration
+
+	cache *blobCache
+}
+
+// NewClient creates a Client backed by bucket. indexesPath/blobsPath should
+// match the worker's configured paths (e.g. "indexes/", "blobs/"). The
+// client assumes blobs were written with blobcodec.Zstd, the worker's
+// default Config.Compression; call WithCodec if the deployment overrides
+// it.
+func NewClient(bucket storage.Bucket, indexesPath, blobsPath string) *Client {
+	return &Client{
+		bucket:         bucket,
+		indexesPath:    indexesPath,
+		blobsPath:      blobsPath,
+		codec:          blobcodec.Zstd,
+		maxConcurrency: 8,
+		maxRetries:     3,
+		retryBackoff:   100 * time.Millisecond,
+		cache:          newBlobCache(256),
+	}
+}
+
+// WithCodec overrides the codec assumed for blob keys and decompression,
+// matching the worker's Config.Compression for the deployment being read
+// (see blobcodec.FromConfig). Returns c for chaining.
+func (c *Client) WithCodec(codec blobcodec.Codec) *Client {
+	c.codec = codec
+	return c
+}
+
+// WithOrg scopes c to a single org, whose dictionaries (see WithDictionary)
+// are loaded from under that org's prefix. Returns c for chaining.
+func (c *Client) WithOrg(org string) *Client {
+	c.org = org
+	return c
+}
+
+// WithOrgScopedKeys tells c that the deployment it's reading was written
+// with cfg.OrgScopedKeys set, so index keys, blob keys, and pack keys are
+// all nested under "orgs/{org_id}/" (see Processor.getBlobKey/getIndexKey
+// and pack.Store). Requires WithOrg to also be set. Returns c for chaining.
+func (c *Client) WithOrgScopedKeys(scoped bool) *Client {
+	c.orgScoped = scoped
+	return c
+}
+
+// WithDictionary enables dictionary-aware zstd decompression, fetching
+// trained dictionaries from dictsPath via a dict.Store backed by c's
+// bucket, the same layout internal/worker/dict.Store writes to. Requires
+// WithOrg to also be set, since dictionaries are trained per org. Returns c
+// for chaining.
+func (c *Client) WithDictionary(dictsPath string) *Client {
+	c.dictStore = dict.NewStore(c.bucket, dictsPath)
+	c.dictsCache = make(map[string][]byte)
+	return c
+}
+
+// WithPacks enables reading chunks that a worker with cfg.PackingEnabled
+// wrote into shared pack objects under packsPath instead of standalone
+// blobs, via a pack.Store backed by c's bucket. Indexes without pack
+// locations (see ChunkIndex.hasPackLocations) are unaffected and still read
+// as standalone blobs. Returns c for chaining.
+func (c *Client) WithPacks(packsPath string) *Client {
+	c.packStore = pack.NewStore(c.bucket, packsPath, 0, 0)
+	return c
+}
+
+// packOrg returns the org to pass to pack.Store calls, mirroring
+// Processor.packOrg: "" (the flat, unscoped layout) unless WithOrgScopedKeys
+// was set.
+func (c *Client) packOrg() string {
+	if c.orgScoped {
+		return c.org
+	}
+	return ""
+}
+
+// withOrgPrefix nests key under "orgs/{org}/" when c.orgScoped is set,
+// matching Processor.getBlobKey/getIndexKey.
+func (c *Client) withOrgPrefix(key string) string {
+	if !c.orgScoped {
+		return key
+	}
+	return fmt.Sprintf("orgs/%s/%s", c.org, key)
+}
+
+// LoadIndex downloads and parses the chunk index for traceID.
+func (c *Client) LoadIndex(ctx context.Context, traceID string) (*ChunkIndex, error) {
+	key := c.withOrgPrefix(fmt.Sprintf("%s%s.json", c.indexesPath, traceID))
+
+	body, err := c.bucket.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("reconstruct: failed to get index: %w", err)
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("reconstruct: failed to read index body: %w", err)
+	}
+
+	var index ChunkIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("reconstruct: failed to parse index JSON: %w", err)
+	}
+	return &index, nil
+}
+
+// ReadAll reconstructs traceID's full content, fetching every chunk
+// concurrently. Indexes written with per-chunk offsets are concatenated
+// directly (offsets assume no separator between chunks); older indexes
+// without offsets fall back to joining chunks with "\n", matching the
+// pre-range-read behavior.
+func (c *Client) ReadAll(ctx context.Context, traceID string) (string, error) {
+	index, err := c.LoadIndex(ctx, traceID)
+	if err != nil {
+		return "", err
+	}
+
+	chunks, err := c.fetchChunks(ctx, index.allRefs())
+	if err != nil {
+		return "", err
+	}
+
+	if index.hasOffsets() {
+		return strings.Join(chunks, ""), nil
+	}
+	return strings.Join(chunks, "\n"), nil
+}
+
+// ReadRange reconstructs only the portion of traceID's content overlapping
+// the half-open byte range [off, off+n), using the index's per-chunk
+// offsets to skip chunks entirely outside the range instead of fetching the
+// whole trace.
+func (c *Client) ReadRange(ctx context.Context, traceID string, off, n int64) (string, error) {
+	index, err := c.LoadIndex(ctx, traceID)
+	if err != nil {
+		return "", err
+	}
+
+	if !index.hasOffsets() {
+		full, err := c.ReadAll(ctx, traceID)
+		if err != nil {
+			return "", err
+		}
+		return sliceRange(full, off, n), nil
+	}
+
+	end := off + n
+	var refs []chunkRef
+	var rangeStart int64
+	found := false
+	for i := range index.Hashes {
+		chunkStart := index.Offsets[i]
+		chunkEnd := chunkStart + index.Sizes[i]
+		if chunkEnd <= off || chunkStart >= end {
+			continue
+		}
+		if !found {
+			rangeStart = chunkStart
+			found = true
+		}
+		refs = append(refs, index.refAt(i))
+	}
+	if !found {
+		return "", nil
+	}
+
+	chunks, err := c.fetchChunks(ctx, refs)
+	if err != nil {
+		return "", err
+	}
+
+	joined := strings.Join(chunks, "")
+	return sliceRange(joined, off-rangeStart, n), nil
+}
+
+// sliceRange returns content[off:off+n], clamped to content's bounds.
+func sliceRange(content string, off, n int64) string {
+	if off < 0 {
+		off = 0
+	}
+	if off >= int64(len(content)) {
+		return ""
+	}
+	end := off + n
+	if end > int64(len(content)) || n < 0 {
+		end = int64(len(content))
+	}
+	return content[off:end]
+}
+
+// fetchChunks downloads and decompresses each ref's blob, preserving input
+// order in the result, using up to maxConcurrency workers and the shared
+// blob cache.
+func (c *Client) fetchChunks(ctx context.Context, refs []chunkRef) ([]string, error) {
+	result := make([]string, len(refs))
+	if len(refs) == 0 {
+		return result, nil
+	}
+
+	sem := make(chan struct{}, c.maxConcurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+
+	for i, ref := range refs {
+		i, ref := i, ref
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			content, err := c.fetchChunkWithRetry(ctx, ref)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("reconstruct: failed to fetch chunk %s: %w", ref.hash, err)
+				}
+				mu.Unlock()
+				return
+			}
+			result[i] = content
+		}()
+	}
+
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return result, nil
+}
+
+// fetchChunkWithRetry fetches and decompresses one chunk, checking the
+// cache first and retrying transient storage errors up to maxRetries times.
+func (c *Client) fetchChunkWithRetry(ctx context.Context, ref chunkRef) (string, error) {
+	if content, ok := c.cache.get(ref.hash); ok {
+		return content, nil
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(c.retryBackoff * time.Duration(attempt)):
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		}
+
+		content, err := c.downloadAndDecompressBlob(ctx, ref)
+		if err == nil {
+			c.cache.put(ref.hash, content)
+			return content, nil
+		}
+		lastErr = err
+	}
+	return "", lastErr
+}
+
+// blobKey returns the object key for hash's standalone blob, matching
+// Processor.getBlobKey's layout: blobs/{hash[0:2]}/{hash}{ext}, where ext
+// reflects c.codec, nested under "orgs/{org_id}/" when WithOrgScopedKeys was
+// set (see withOrgPrefix).
+func (c *Client) blobKey(hash string) string {
+	return c.withOrgPrefix(fmt.Sprintf("%s%s/%s%s", c.blobsPath, hash[:2], hash, c.codec.Ext()))
+}
+
+// downloadAndDecompressBlob fetches a single chunk's raw blob -- from its
+// pack via a ranged read if ref carries a pack location, otherwise from its
+// standalone object -- and decompresses it, dispatching on the codec magic
+// header blobcodec.Encode wrote rather than assuming gzip.
+func (c *Client) downloadAndDecompressBlob(ctx context.Context, ref chunkRef) (string, error) {
+	raw, err := c.fetchRawBlob(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	return c.decodeBlob(ctx, raw)
+}
+
+// fetchRawBlob returns ref's still-compressed blob bytes, unchanged since
+// they were written (magic header and all).
+func (c *Client) fetchRawBlob(ctx context.Context, ref chunkRef) ([]byte, error) {
+	if ref.packID != "" {
+		if c.packStore == nil {
+			return nil, fmt.Errorf("chunk %s is stored in pack %s; Client needs WithPacks configured to read it", ref.hash, ref.packID)
+		}
+		loc := pack.Location{PackID: ref.packID, Offset: ref.packOffset, Length: ref.packLength}
+		raw, err := c.packStore.Get(ctx, c.packOrg(), loc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get packed blob: %w", err)
+		}
+		return raw, nil
+	}
+
+	body, err := c.bucket.Get(ctx, c.blobKey(ref.hash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blob: %w", err)
+	}
+	defer body.Close()
+
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob: %w", err)
+	}
+	return raw, nil
+}
+
+// decodeBlob decodes raw's magic header and decompresses its payload
+// according to the codec it was actually stored with, loading the
+// dictionary named in the header (see WithDictionary) for zstd blobs
+// compressed against one.
+func (c *Client) decodeBlob(ctx context.Context, raw []byte) (string, error) {
+	codec, dictVersion, data, err := blobcodec.Decode(raw)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode blob: %w", err)
+	}
+
+	switch codec {
+	case blobcodec.Gzip:
+		out, err := blobcodec.GzipDecompress(data)
+		if err != nil {
+			return "", fmt.Errorf("failed to gunzip blob: %w", err)
+		}
+		return string(out), nil
+	case blobcodec.None:
+		return string(data), nil
+	default:
+		var dictBytes []byte
+		if dictVersion != "" && dictVersion != "none" {
+			dictBytes, err = c.loadDictionary(ctx, dictVersion)
+			if err != nil {
+				return "", err
+			}
+		}
+		out, err := blobcodec.ZstdDecompress(data, dictBytes)
+		if err != nil {
+			return "", fmt.Errorf("failed to decompress zstd blob: %w", err)
+		}
+		return string(out), nil
+	}
+}
+
+// loadDictionary returns the trained dictionary bytes for version, fetching
+// it via c.dictStore on first use and caching the result for subsequent
+// blobs compressed against the same version.
+func (c *Client) loadDictionary(ctx context.Context, version string) ([]byte, error) {
+	if c.dictStore == nil || c.org == "" {
+		return nil, fmt.Errorf("blob compressed against dictionary %q; Client needs WithOrg and WithDictionary configured to decompress it", version)
+	}
+
+	cacheKey := c.org + "/" + version
+	c.dictsMu.Lock()
+	defer c.dictsMu.Unlock()
+
+	if b, ok := c.dictsCache[cacheKey]; ok {
+		return b, nil
+	}
+
+	b, err := c.dictStore.LoadDictionary(ctx, c.org, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load dictionary %s: %w", cacheKey, err)
+	}
+	c.dictsCache[cacheKey] = b
+	return b, nil
+}
@@ -0,0 +1,449 @@
+package reconstruct
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/youware/gravity/internal/blobcodec"
+	"github.com/youware/gravity/internal/storage"
+	"github.com/youware/gravity/internal/worker/dict"
+	"github.com/youware/gravity/internal/worker/pack"
+)
+
+// testDictionary builds a real zstd dictionary (the wire format zstd's
+// encoder/decoder require, as opposed to arbitrary bytes) over repeated
+// sample content, for tests exercising dictionary-aware decompression.
+func testDictionary(t *testing.T) []byte {
+	t.Helper()
+	contents := [][]byte{
+		[]byte("You are a helpful assistant. Tool schema: {\"name\": \"search\", \"args\": [\"query\"]}"),
+		[]byte("You are a careful assistant. Tool schema: {\"name\": \"fetch\", \"args\": [\"url\"]}"),
+		[]byte("You are a concise assistant. Tool schema: {\"name\": \"write\", \"args\": [\"path\", \"content\"]}"),
+	}
+	d, err := zstd.BuildDict(zstd.BuildDictOptions{
+		ID:       1,
+		Contents: contents,
+		History:  contents[len(contents)-1],
+		Offsets:  [3]int{1, 1, 1},
+	})
+	if err != nil {
+		t.Fatalf("BuildDict: %v", err)
+	}
+	return d
+}
+
+// putBlob compresses content with codec and stores it at the blob key a
+// Client expects for hash, mirroring what the worker's storeBlob writes.
+func putBlob(t *testing.T, ctx context.Context, bucket storage.Bucket, blobsPath, hash, content string, codec blobcodec.Codec) {
+	t.Helper()
+
+	var compressed []byte
+	switch codec {
+	case blobcodec.Gzip:
+		var err error
+		compressed, err = blobcodec.GzipCompress([]byte(content))
+		if err != nil {
+			t.Fatalf("gzip compress: %v", err)
+		}
+	case blobcodec.None:
+		compressed = []byte(content)
+	default:
+		enc, err := blobcodec.ZstdCompress([]byte(content), nil)
+		if err != nil {
+			t.Fatalf("zstd compress: %v", err)
+		}
+		compressed = enc
+	}
+
+	payload := blobcodec.Encode(codec, "", compressed)
+	key := fmt.Sprintf("%s%s/%s%s", blobsPath, hash[:2], hash, codec.Ext())
+	if err := bucket.Put(ctx, key, codec.ContentType(), bytes.NewReader(payload), int64(len(payload))); err != nil {
+		t.Fatalf("Put blob %s: %v", hash, err)
+	}
+}
+
+// putZstdBlob is putBlob fixed to the Client's default codec.
+func putZstdBlob(t *testing.T, ctx context.Context, bucket storage.Bucket, blobsPath, hash, content string) {
+	t.Helper()
+	putBlob(t, ctx, bucket, blobsPath, hash, content, blobcodec.Zstd)
+}
+
+func putIndex(t *testing.T, ctx context.Context, bucket storage.Bucket, indexesPath string, index ChunkIndex) {
+	t.Helper()
+
+	data, err := json.Marshal(index)
+	if err != nil {
+		t.Fatalf("marshal index: %v", err)
+	}
+	key := fmt.Sprintf("%s%s.json", indexesPath, index.TraceID)
+	if err := bucket.Put(ctx, key, "application/json", bytes.NewReader(data), int64(len(data))); err != nil {
+		t.Fatalf("Put index: %v", err)
+	}
+}
+
+func TestClientReadAll(t *testing.T) {
+	bucket, err := storage.NewFSBucket(storage.FSConfig{RootDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("NewFSBucket: %v", err)
+	}
+	ctx := context.Background()
+
+	putZstdBlob(t, ctx, bucket, "blobs/", "aaaa1111", "hello ")
+	putZstdBlob(t, ctx, bucket, "blobs/", "bbbb2222", "world")
+	putIndex(t, ctx, bucket, "indexes/", ChunkIndex{
+		TraceID: "trace-1",
+		SpanID:  "span-1",
+		Hashes:  []string{"aaaa1111", "bbbb2222"},
+		Sizes:   []int64{6, 5},
+		Offsets: []int64{0, 6},
+	})
+
+	client := NewClient(bucket, "indexes/", "blobs/")
+	content, err := client.ReadAll(ctx, "trace-1")
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if content != "hello world" {
+		t.Errorf("ReadAll = %q, want %q", content, "hello world")
+	}
+}
+
+func TestClientReadAllLegacyIndex(t *testing.T) {
+	bucket, err := storage.NewFSBucket(storage.FSConfig{RootDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("NewFSBucket: %v", err)
+	}
+	ctx := context.Background()
+
+	putZstdBlob(t, ctx, bucket, "blobs/", "cccc3333", "line one")
+	putZstdBlob(t, ctx, bucket, "blobs/", "dddd4444", "line two")
+	putIndex(t, ctx, bucket, "indexes/", ChunkIndex{
+		TraceID: "trace-2",
+		SpanID:  "span-2",
+		Hashes:  []string{"cccc3333", "dddd4444"},
+	})
+
+	client := NewClient(bucket, "indexes/", "blobs/")
+	content, err := client.ReadAll(ctx, "trace-2")
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if content != "line one\nline two" {
+		t.Errorf("ReadAll = %q, want %q", content, "line one\nline two")
+	}
+}
+
+func TestClientReadRange(t *testing.T) {
+	bucket, err := storage.NewFSBucket(storage.FSConfig{RootDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("NewFSBucket: %v", err)
+	}
+	ctx := context.Background()
+
+	putZstdBlob(t, ctx, bucket, "blobs/", "ee001111", "0123456789")
+	putZstdBlob(t, ctx, bucket, "blobs/", "ee002222", "abcdefghij")
+	putZstdBlob(t, ctx, bucket, "blobs/", "ee003333", "klmnopqrst")
+	putIndex(t, ctx, bucket, "indexes/", ChunkIndex{
+		TraceID: "trace-3",
+		SpanID:  "span-3",
+		Hashes:  []string{"ee001111", "ee002222", "ee003333"},
+		Sizes:   []int64{10, 10, 10},
+		Offsets: []int64{0, 10, 20},
+	})
+
+	client := NewClient(bucket, "indexes/", "blobs/")
+	content, err := client.ReadRange(ctx, "trace-3", 8, 6)
+	if err != nil {
+		t.Fatalf("ReadRange: %v", err)
+	}
+	want := "0123456789abcdefghijklmnopqrst"[8:14]
+	if content != want {
+		t.Errorf("ReadRange(8, 6) = %q, want %q", content, want)
+	}
+}
+
+// TestClientReadAllGzipCodec exercises a deployment configured with
+// Compression=gzip, which stores blobs under a different key suffix and
+// codec than the zstd default the other tests use.
+func TestClientReadAllGzipCodec(t *testing.T) {
+	bucket, err := storage.NewFSBucket(storage.FSConfig{RootDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("NewFSBucket: %v", err)
+	}
+	ctx := context.Background()
+
+	putBlob(t, ctx, bucket, "blobs/", "ff001111", "hello ", blobcodec.Gzip)
+	putBlob(t, ctx, bucket, "blobs/", "ff002222", "gzip", blobcodec.Gzip)
+	putIndex(t, ctx, bucket, "indexes/", ChunkIndex{
+		TraceID: "trace-4",
+		SpanID:  "span-4",
+		Hashes:  []string{"ff001111", "ff002222"},
+		Sizes:   []int64{6, 4},
+		Offsets: []int64{0, 6},
+	})
+
+	client := NewClient(bucket, "indexes/", "blobs/").WithCodec(blobcodec.Gzip)
+	content, err := client.ReadAll(ctx, "trace-4")
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if content != "hello gzip" {
+		t.Errorf("ReadAll = %q, want %q", content, "hello gzip")
+	}
+}
+
+// TestClientReadAllWithDictionary exercises a chunk compressed against a
+// trained per-org zstd dictionary, matching a deployment where
+// internal/worker/dict has trained and stored one for the org.
+func TestClientReadAllWithDictionary(t *testing.T) {
+	bucket, err := storage.NewFSBucket(storage.FSConfig{RootDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("NewFSBucket: %v", err)
+	}
+	ctx := context.Background()
+
+	dictBytes := testDictionary(t)
+	if err := dict.NewStore(bucket, "dicts/").SaveDictionary(ctx, "org-1", "v1", dictBytes); err != nil {
+		t.Fatalf("SaveDictionary: %v", err)
+	}
+
+	const hash, content = "dd001111", "the quick brown fox"
+	compressed, err := blobcodec.ZstdCompress([]byte(content), dictBytes)
+	if err != nil {
+		t.Fatalf("ZstdCompress: %v", err)
+	}
+	payload := blobcodec.Encode(blobcodec.Zstd, "v1", compressed)
+	key := fmt.Sprintf("blobs/%s/%s%s", hash[:2], hash, blobcodec.Zstd.Ext())
+	if err := bucket.Put(ctx, key, blobcodec.Zstd.ContentType(), bytes.NewReader(payload), int64(len(payload))); err != nil {
+		t.Fatalf("Put blob: %v", err)
+	}
+	putIndex(t, ctx, bucket, "indexes/", ChunkIndex{
+		TraceID: "trace-5",
+		SpanID:  "span-5",
+		Hashes:  []string{hash},
+		Sizes:   []int64{int64(len(content))},
+		Offsets: []int64{0},
+		DictID:  "v1",
+	})
+
+	client := NewClient(bucket, "indexes/", "blobs/").WithOrg("org-1").WithDictionary("dicts/")
+	got, err := client.ReadAll(ctx, "trace-5")
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if got != content {
+		t.Errorf("ReadAll = %q, want %q", got, content)
+	}
+}
+
+// TestClientReadAllWithDictionaryMissingOrg exercises the error path when a
+// blob references a dictionary but the Client wasn't configured with
+// WithOrg/WithDictionary to load one.
+func TestClientReadAllWithDictionaryMissingOrg(t *testing.T) {
+	bucket, err := storage.NewFSBucket(storage.FSConfig{RootDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("NewFSBucket: %v", err)
+	}
+	ctx := context.Background()
+
+	dictBytes := testDictionary(t)
+	const hash, content = "dd002222", "the quick brown fox"
+	compressed, err := blobcodec.ZstdCompress([]byte(content), dictBytes)
+	if err != nil {
+		t.Fatalf("ZstdCompress: %v", err)
+	}
+	payload := blobcodec.Encode(blobcodec.Zstd, "v1", compressed)
+	key := fmt.Sprintf("blobs/%s/%s%s", hash[:2], hash, blobcodec.Zstd.Ext())
+	if err := bucket.Put(ctx, key, blobcodec.Zstd.ContentType(), bytes.NewReader(payload), int64(len(payload))); err != nil {
+		t.Fatalf("Put blob: %v", err)
+	}
+	putIndex(t, ctx, bucket, "indexes/", ChunkIndex{
+		TraceID: "trace-6",
+		SpanID:  "span-6",
+		Hashes:  []string{hash},
+		Sizes:   []int64{int64(len(content))},
+		Offsets: []int64{0},
+		DictID:  "v1",
+	})
+
+	client := NewClient(bucket, "indexes/", "blobs/")
+	if _, err := client.ReadAll(ctx, "trace-6"); err == nil {
+		t.Fatal("ReadAll: expected error for dictionary-compressed blob without WithOrg/WithDictionary, got nil")
+	}
+}
+
+// TestClientReadAllPacked exercises chunks written through a real
+// pack.Store (the same component a worker with cfg.PackingEnabled uses)
+// instead of standalone blobs, verifying the index's PackIDs/PackOffsets/
+// PackLengths route each chunk to a ranged read against its pack object.
+func TestClientReadAllPacked(t *testing.T) {
+	bucket, err := storage.NewFSBucket(storage.FSConfig{RootDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("NewFSBucket: %v", err)
+	}
+	ctx := context.Background()
+
+	packStore := pack.NewStore(bucket, "packs/", 0, 0)
+
+	hashes := []string{"ee991111", "ee992222"}
+	contents := []string{"packed hello ", "packed world"}
+	var packIDs []string
+	var packOffsets, packLengths []int64
+	for i, content := range contents {
+		compressed, err := blobcodec.ZstdCompress([]byte(content), nil)
+		if err != nil {
+			t.Fatalf("ZstdCompress: %v", err)
+		}
+		payload := blobcodec.Encode(blobcodec.Zstd, "", compressed)
+		loc, err := packStore.Append(ctx, "", hashes[i], payload)
+		if err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+		packIDs = append(packIDs, loc.PackID)
+		packOffsets = append(packOffsets, loc.Offset)
+		packLengths = append(packLengths, loc.Length)
+	}
+	if err := packStore.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	putIndex(t, ctx, bucket, "indexes/", ChunkIndex{
+		TraceID:     "trace-7",
+		SpanID:      "span-7",
+		Hashes:      hashes,
+		Sizes:       []int64{13, 12},
+		Offsets:     []int64{0, 13},
+		PackIDs:     packIDs,
+		PackOffsets: packOffsets,
+		PackLengths: packLengths,
+	})
+
+	client := NewClient(bucket, "indexes/", "blobs/").WithPacks("packs/")
+	got, err := client.ReadAll(ctx, "trace-7")
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if want := "packed hello packed world"; got != want {
+		t.Errorf("ReadAll = %q, want %q", got, want)
+	}
+}
+
+// TestClientReadAllPackedMissingWithPacks exercises the error path when an
+// index references pack locations but the Client wasn't configured with
+// WithPacks to read them.
+func TestClientReadAllPackedMissingWithPacks(t *testing.T) {
+	bucket, err := storage.NewFSBucket(storage.FSConfig{RootDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("NewFSBucket: %v", err)
+	}
+	ctx := context.Background()
+
+	packStore := pack.NewStore(bucket, "packs/", 0, 0)
+	compressed, err := blobcodec.ZstdCompress([]byte("packed"), nil)
+	if err != nil {
+		t.Fatalf("ZstdCompress: %v", err)
+	}
+	payload := blobcodec.Encode(blobcodec.Zstd, "", compressed)
+	loc, err := packStore.Append(ctx, "", "ee993333", payload)
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if err := packStore.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	putIndex(t, ctx, bucket, "indexes/", ChunkIndex{
+		TraceID:     "trace-8",
+		SpanID:      "span-8",
+		Hashes:      []string{"ee993333"},
+		Sizes:       []int64{6},
+		Offsets:     []int64{0},
+		PackIDs:     []string{loc.PackID},
+		PackOffsets: []int64{loc.Offset},
+		PackLengths: []int64{loc.Length},
+	})
+
+	client := NewClient(bucket, "indexes/", "blobs/")
+	if _, err := client.ReadAll(ctx, "trace-8"); err == nil {
+		t.Fatal("ReadAll: expected error for packed chunk without WithPacks, got nil")
+	}
+}
+
+// TestClientReadAllOrgScopedPacked exercises WithOrgScopedKeys against a
+// deployment with both OrgScopedKeys and PackingEnabled, verifying the
+// index, and the pack it references, are both read from under
+// "orgs/{org}/" -- and that a second org's same-hash chunk packed separately
+// doesn't collide with or leak into the first org's pack object.
+func TestClientReadAllOrgScopedPacked(t *testing.T) {
+	bucket, err := storage.NewFSBucket(storage.FSConfig{RootDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("NewFSBucket: %v", err)
+	}
+	ctx := context.Background()
+
+	packStore := pack.NewStore(bucket, "packs/", 0, 0)
+
+	writeOrgTrace := func(org, traceID, hash, content string) pack.Location {
+		compressed, err := blobcodec.ZstdCompress([]byte(content), nil)
+		if err != nil {
+			t.Fatalf("ZstdCompress: %v", err)
+		}
+		payload := blobcodec.Encode(blobcodec.Zstd, "", compressed)
+		loc, err := packStore.Append(ctx, org, hash, payload)
+		if err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+		if err := packStore.Flush(ctx); err != nil {
+			t.Fatalf("Flush: %v", err)
+		}
+
+		index := ChunkIndex{
+			TraceID:     traceID,
+			SpanID:      traceID,
+			Hashes:      []string{hash},
+			Sizes:       []int64{int64(len(content))},
+			Offsets:     []int64{0},
+			PackIDs:     []string{loc.PackID},
+			PackOffsets: []int64{loc.Offset},
+			PackLengths: []int64{loc.Length},
+		}
+		data, err := json.Marshal(index)
+		if err != nil {
+			t.Fatalf("marshal index: %v", err)
+		}
+		key := fmt.Sprintf("orgs/%s/indexes/%s.json", org, traceID)
+		if err := bucket.Put(ctx, key, "application/json", bytes.NewReader(data), int64(len(data))); err != nil {
+			t.Fatalf("Put index: %v", err)
+		}
+		return loc
+	}
+
+	locA := writeOrgTrace("org-a", "trace-9", "ff994444", "org a content")
+	locB := writeOrgTrace("org-b", "trace-9", "ff994444", "org b content")
+	if locA.PackID == locB.PackID {
+		t.Fatalf("expected org-a and org-b to land in different packs, both got %s", locA.PackID)
+	}
+
+	clientA := NewClient(bucket, "indexes/", "blobs/").WithOrg("org-a").WithOrgScopedKeys(true).WithPacks("packs/")
+	gotA, err := clientA.ReadAll(ctx, "trace-9")
+	if err != nil {
+		t.Fatalf("ReadAll(org-a): %v", err)
+	}
+	if want := "org a content"; gotA != want {
+		t.Errorf("ReadAll(org-a) = %q, want %q", gotA, want)
+	}
+
+	clientB := NewClient(bucket, "indexes/", "blobs/").WithOrg("org-b").WithOrgScopedKeys(true).WithPacks("packs/")
+	gotB, err := clientB.ReadAll(ctx, "trace-9")
+	if err != nil {
+		t.Fatalf("ReadAll(org-b): %v", err)
+	}
+	if want := "org b content"; gotB != want {
+		t.Errorf("ReadAll(org-b) = %q, want %q", gotB, want)
+	}
+}
@@ -4,12 +4,56 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // Config holds all application configuration
 type Config struct {
-	HTTP HTTPConfig
+	HTTP   HTTPConfig
+	GRPC   GRPCConfig
+	Admin  AdminConfig
+	Queue  QueueConfig
+	Filter FilterConfig
+	Auth   AuthConfig
+	Repro  ReproducerConfig
+}
+
+// ReproducerConfig controls capture of ingest requests for later replay
+// against a dev endpoint, so production 400/500s can be reproduced
+// deterministically instead of debugged from logs alone.
+type ReproducerConfig struct {
+	Enabled bool
+	// Dir is the local directory captured artifacts are written to.
+	Dir string
+	// SampleRate additionally captures this fraction of successful
+	// requests, on top of every request that fails to decode or process.
+	SampleRate float64
+}
+
+// AuthConfig controls access-key authentication on the OTLP ingest
+// endpoints. When disabled, handlers fall back to trusting the
+// client-supplied x-org-id header.
+type AuthConfig struct {
+	Enabled bool
+	DBPath  string // bbolt file backing the access-key store
+}
+
+// FilterConfig configures the ingest span filter, including operator-defined
+// CEL expressions evaluated in addition to the SpanNames/SpanKinds allowlists.
+type FilterConfig struct {
+	Expressions []string
+}
+
+// QueueConfig selects and configures the queue backend the ingest handler
+// publishes envelopes to, in addition to the in-process pipeline.
+type QueueConfig struct {
+	Enabled       bool
+	Type          string // "kafka" or "nats"
+	Brokers       []string
+	Topic         string
+	ConsumerGroup string
+	MaxRetries    int
 }
 
 // HTTPConfig holds HTTP server configuration
@@ -18,6 +62,24 @@ type HTTPConfig struct {
 	ReadTimeout     time.Duration
 	WriteTimeout    time.Duration
 	ShutdownTimeout time.Duration
+	// MaxBodyBytes bounds the size of an incoming (pre-decompression)
+	// request body; requests over this size get a 413 before BodyMiddleware
+	// even attempts to decode Content-Encoding.
+	MaxBodyBytes int64
+}
+
+// GRPCConfig holds OTLP/gRPC server configuration
+type GRPCConfig struct {
+	// Enabled controls whether the gRPC OTLP listener is started alongside HTTP
+	Enabled bool
+	Address string
+}
+
+// AdminConfig holds the admin/observability server configuration, served on
+// a separate port from ingestion so metrics scraping never competes with it.
+type AdminConfig struct {
+	Enabled bool
+	Address string
 }
 
 // Load reads configuration from environment variables with sensible defaults
@@ -28,6 +90,35 @@ func Load() (*Config, error) {
 			ReadTimeout:     getDuration("HTTP_READ_TIMEOUT", 10*time.Second),
 			WriteTimeout:    getDuration("HTTP_WRITE_TIMEOUT", 10*time.Second),
 			ShutdownTimeout: getDuration("HTTP_SHUTDOWN_TIMEOUT", 30*time.Second),
+			MaxBodyBytes:    getEnvInt64("HTTP_MAX_BODY_BYTES", 64<<20),
+		},
+		GRPC: GRPCConfig{
+			Enabled: getEnvBool("GRPC_ENABLED", true),
+			Address: getEnv("GRPC_ADDRESS", ":4317"),
+		},
+		Admin: AdminConfig{
+			Enabled: getEnvBool("ADMIN_ENABLED", true),
+			Address: getEnv("ADMIN_ADDRESS", ":9090"),
+		},
+		Queue: QueueConfig{
+			Enabled:       getEnvBool("QUEUE_ENABLED", false),
+			Type:          getEnv("QUEUE_TYPE", "kafka"),
+			Brokers:       getEnvSlice("QUEUE_BROKERS", nil),
+			Topic:         getEnv("QUEUE_TOPIC", "gravity-envelopes"),
+			ConsumerGroup: getEnv("QUEUE_CONSUMER_GROUP", "gravity-worker"),
+			MaxRetries:    getEnvInt("QUEUE_MAX_RETRIES", 3),
+		},
+		Filter: FilterConfig{
+			Expressions: getEnvSlice("FILTER_EXPRESSIONS", nil),
+		},
+		Auth: AuthConfig{
+			Enabled: getEnvBool("AUTH_ENABLED", false),
+			DBPath:  getEnv("AUTH_DB_PATH", "gravity-keys.db"),
+		},
+		Repro: ReproducerConfig{
+			Enabled:    getEnvBool("REPRODUCER_ENABLED", false),
+			Dir:        getEnv("REPRODUCER_DIR", "gravity-captures"),
+			SampleRate: getEnvFloat("REPRODUCER_SAMPLE_RATE", 0),
 		},
 	}
 
@@ -52,6 +143,36 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvBool gets a boolean environment variable or returns a default value
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolVal, err := strconv.ParseBool(value); err == nil {
+			return boolVal
+		}
+	}
+	return defaultValue
+}
+
+// getEnvInt64 gets an int64 environment variable or returns a default value
+func getEnvInt64(key string, defaultValue int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		if intVal, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return intVal
+		}
+	}
+	return defaultValue
+}
+
+// getEnvFloat gets a float environment variable or returns a default value
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatVal
+		}
+	}
+	return defaultValue
+}
+
 // getDuration gets a duration environment variable or returns a default value
 func getDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
@@ -65,7 +186,7 @@ func getDuration(key string, defaultValue time.Duration) time.Duration {
 // getEnvSlice gets a comma-separated environment variable as a slice
 func getEnvSlice(key string, defaultValue []string) []string {
 	if value := os.Getenv(key); value != "" {
-		return []string{value}
+		return strings.Split(value, ",")
 	}
 	return defaultValue
 }
@@ -76,5 +197,17 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("HTTP address cannot be empty")
 	}
 
+	if c.GRPC.Enabled && c.GRPC.Address == "" {
+		return fmt.Errorf("gRPC address cannot be empty when gRPC is enabled")
+	}
+
+	if c.Auth.Enabled && c.Auth.DBPath == "" {
+		return fmt.Errorf("auth DB path cannot be empty when auth is enabled")
+	}
+
+	if c.Repro.Enabled && c.Repro.Dir == "" {
+		return fmt.Errorf("reproducer capture dir cannot be empty when reproducer is enabled")
+	}
+
 	return nil
 }
@@ -0,0 +1,63 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Prometheus-compatible metrics exported on the admin port. These describe
+// Gravity's own ingestion behavior, complementing the OTLP traces/metrics it
+// stores on behalf of callers.
+var (
+	// IngestThroughput counts spans accepted into the pipeline, labeled by org_id.
+	IngestThroughput = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gravity_ingest_spans_total",
+		Help: "Total number of spans accepted into the ingest pipeline.",
+	}, []string{"org_id"})
+
+	// FilterDropReasons counts spans rejected by the span filter, labeled by reason.
+	FilterDropReasons = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gravity_ingest_filtered_total",
+		Help: "Total number of spans dropped by the ingest filter, by reason.",
+	}, []string{"reason"})
+
+	// ConversionLatency observes how long convertOTLPToEnvelopes takes per batch.
+	ConversionLatency = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "gravity_ingest_conversion_duration_seconds",
+		Help:    "Latency of converting an OTLP export request into envelopes.",
+		Buckets: prometheus.DefBuckets,
+	})
+)
+
+// AdminServer exposes Prometheus metrics on a separate port from the
+// ingestion listener, so scraping never competes with request handling.
+type AdminServer struct {
+	httpServer *http.Server
+}
+
+// NewAdminServer creates an admin server that serves /metrics on address.
+func NewAdminServer(address string) *AdminServer {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	return &AdminServer{
+		httpServer: &http.Server{
+			Addr:    address,
+			Handler: mux,
+		},
+	}
+}
+
+// Start begins serving the admin endpoints. It blocks until the server stops.
+func (a *AdminServer) Start() error {
+	return a.httpServer.ListenAndServe()
+}
+
+// Shutdown gracefully stops the admin server.
+func (a *AdminServer) Shutdown(ctx context.Context) error {
+	return a.httpServer.Shutdown(ctx)
+}
@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HTTPMiddleware starts one span per request, recording status, bytes
+// written, duration, and the chi request ID as a span attribute. It is
+// intended to sit alongside the existing chi middleware stack.
+func HTTPMiddleware(next http.Handler) http.Handler {
+	tracer := otel.Tracer(TracerName)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		ctx, span := tracer.Start(r.Context(), r.Method+" "+r.URL.Path,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.method", r.Method),
+				attribute.String("http.path", r.URL.Path),
+			),
+		)
+		defer span.End()
+
+		if reqID := middleware.GetReqID(r.Context()); reqID != "" {
+			span.SetAttributes(attribute.String("request_id", reqID))
+		}
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		span.SetAttributes(
+			attribute.Int("http.status_code", status),
+			attribute.Int("http.response_bytes", ww.BytesWritten()),
+			attribute.Int64("duration_ms", time.Since(start).Milliseconds()),
+		)
+
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	})
+}
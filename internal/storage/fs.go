@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FSConfig configures the local-filesystem adapter, used for local dev and
+// the adapter contract test suite.
+type FSConfig struct {
+	RootDir string
+}
+
+// FSBucket implements Bucket against a directory tree, mapping object keys
+// straight onto relative paths below RootDir.
+type FSBucket struct {
+	root string
+}
+
+// NewFSBucket creates an FSBucket rooted at cfg.RootDir, creating it if
+// necessary.
+func NewFSBucket(cfg FSConfig) (*FSBucket, error) {
+	if err := os.MkdirAll(cfg.RootDir, 0755); err != nil {
+		return nil, err
+	}
+	return &FSBucket{root: cfg.RootDir}, nil
+}
+
+func (b *FSBucket) path(key string) string {
+	return filepath.Join(b.root, filepath.FromSlash(key))
+}
+
+// Get implements Bucket.
+func (b *FSBucket) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	f, err := os.Open(b.path(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotExist
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+// Put implements Bucket.
+func (b *FSBucket) Put(ctx context.Context, key, contentType string, r io.Reader, size int64) error {
+	dest := b.path(key)
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, r)
+	return err
+}
+
+// Head implements Bucket.
+func (b *FSBucket) Head(ctx context.Context, key string) (bool, error) {
+	_, err := os.Stat(b.path(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// List implements Bucket. token is the offset into the sorted key list,
+// encoded as a decimal string, since the filesystem has no native paging.
+func (b *FSBucket) List(ctx context.Context, prefix, token string) ([]string, string, error) {
+	const pageSize = 1000
+
+	var all []string
+	err := filepath.Walk(b.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		key := filepath.ToSlash(strings.TrimPrefix(path, b.root+string(filepath.Separator)))
+		if strings.HasPrefix(key, prefix) {
+			all = append(all, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	sort.Strings(all)
+
+	offset := 0
+	if token != "" {
+		offset, err = strconv.Atoi(token)
+		if err != nil {
+			return nil, "", errors.New("storage: invalid list token")
+		}
+	}
+	if offset >= len(all) {
+		return nil, "", nil
+	}
+
+	end := offset + pageSize
+	if end > len(all) {
+		end = len(all)
+	}
+
+	next := ""
+	if end < len(all) {
+		next = strconv.Itoa(end)
+	}
+	return all[offset:end], next, nil
+}
+
+// Delete implements Bucket.
+func (b *FSBucket) Delete(ctx context.Context, key string) error {
+	err := os.Remove(b.path(key))
+	if err != nil && errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// NewRangeReader implements Bucket.
+func (b *FSBucket) NewRangeReader(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	f, err := os.Open(b.path(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotExist
+		}
+		return nil, err
+	}
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if length < 0 {
+		return f, nil
+	}
+	return &limitedReadCloser{r: io.LimitReader(f, length), c: f}, nil
+}
+
+// limitedReadCloser pairs an io.LimitReader with the underlying file's Close.
+type limitedReadCloser struct {
+	r io.Reader
+	c io.Closer
+}
+
+func (l *limitedReadCloser) Read(p []byte) (int, error) { return l.r.Read(p) }
+func (l *limitedReadCloser) Close() error               { return l.c.Close() }
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// GCSConfig configures the Google Cloud Storage adapter.
+type GCSConfig struct {
+	Bucket string
+}
+
+// GCSBucket implements Bucket on top of the Google Cloud Storage client.
+type GCSBucket struct {
+	client *storage.Client
+	bucket string
+}
+
+// NewGCSBucket creates a GCSBucket from cfg, using application-default
+// credentials.
+func NewGCSBucket(ctx context.Context, cfg GCSConfig) (*GCSBucket, error) {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &GCSBucket{client: client, bucket: cfg.Bucket}, nil
+}
+
+func (b *GCSBucket) object(key string) *storage.ObjectHandle {
+	return b.client.Bucket(b.bucket).Object(key)
+}
+
+// Get implements Bucket.
+func (b *GCSBucket) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	r, err := b.object(key).NewReader(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return nil, ErrNotExist
+		}
+		return nil, err
+	}
+	return r, nil
+}
+
+// Put implements Bucket.
+func (b *GCSBucket) Put(ctx context.Context, key, contentType string, r io.Reader, size int64) error {
+	w := b.object(key).NewWriter(ctx)
+	w.ContentType = contentType
+
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// Head implements Bucket.
+func (b *GCSBucket) Head(ctx context.Context, key string) (bool, error) {
+	_, err := b.object(key).Attrs(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// List implements Bucket. token is the GCS page token.
+func (b *GCSBucket) List(ctx context.Context, prefix, token string) ([]string, string, error) {
+	it := b.client.Bucket(b.bucket).Objects(ctx, &storage.Query{Prefix: prefix})
+
+	pager := iterator.NewPager(it, 1000, token)
+	var attrs []*storage.ObjectAttrs
+	next, err := pager.NextPage(&attrs)
+	if err != nil {
+		return nil, "", err
+	}
+
+	keys := make([]string, 0, len(attrs))
+	for _, a := range attrs {
+		keys = append(keys, a.Name)
+	}
+	return keys, next, nil
+}
+
+// Delete implements Bucket.
+func (b *GCSBucket) Delete(ctx context.Context, key string) error {
+	err := b.object(key).Delete(ctx)
+	if err != nil && errors.Is(err, storage.ErrObjectNotExist) {
+		return nil
+	}
+	return err
+}
+
+// NewRangeReader implements Bucket.
+func (b *GCSBucket) NewRangeReader(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	r, err := b.object(key).NewRangeReader(ctx, offset, length)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return nil, ErrNotExist
+		}
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,82 @@
+// Package storage abstracts the object-storage operations Gravity's worker,
+// reconstructor, and index writers need, so none of them bind directly to a
+// single provider's SDK. Adapters exist for AWS S3, Aliyun OSS, GCS, and the
+// local filesystem (for dev and tests); all are selected via Config.Provider.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNotExist is returned by Head/Get/NewRangeReader when the requested key
+// doesn't exist. Adapters translate their provider-specific not-found error
+// into this so callers can use errors.Is instead of string matching.
+var ErrNotExist = errors.New("storage: object does not exist")
+
+// Object describes a single entry returned by List.
+type Object struct {
+	Key  string
+	Size int64
+}
+
+// Bucket is the storage operations the worker, reconstructor, and index
+// writers need. Implementations must be safe for concurrent use.
+type Bucket interface {
+	// Get returns the full contents of key. The caller must Close the reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Put writes r (size bytes) to key, overwriting any existing object.
+	Put(ctx context.Context, key, contentType string, r io.Reader, size int64) error
+	// Head reports whether key exists.
+	Head(ctx context.Context, key string) (bool, error)
+	// List returns keys under prefix, paginating via token (empty for the
+	// first page); next is empty once there are no more pages.
+	List(ctx context.Context, prefix, token string) (keys []string, next string, err error)
+	// Delete removes key. Deleting a key that doesn't exist is not an error.
+	Delete(ctx context.Context, key string) error
+	// NewRangeReader returns a reader over [offset, offset+length) of key's
+	// content. length < 0 reads to the end of the object.
+	NewRangeReader(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+}
+
+// Presigner is implemented by Bucket adapters that can mint time-bounded,
+// credential-free GET URLs for a key (currently S3Bucket only). Callers
+// should type-assert a Bucket to Presigner and handle the !ok case rather
+// than assuming every provider supports it.
+type Presigner interface {
+	// PresignGet returns a GET URL for key that expires after ttl.
+	PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// Config selects and configures a Bucket implementation. Only the section
+// matching Provider is read.
+type Config struct {
+	Provider string // "s3", "s3compat", "oss", "gcs", or "fs"
+
+	S3       S3Config
+	OSS      OSSConfig
+	GCS      GCSConfig
+	FS       FSConfig
+}
+
+// New builds the Bucket selected by cfg.Provider.
+func New(ctx context.Context, cfg Config) (Bucket, error) {
+	switch cfg.Provider {
+	case "", "s3":
+		return NewS3Bucket(ctx, cfg.S3)
+	case "s3compat":
+		compat := cfg.S3
+		compat.PathStyle = true
+		return NewS3Bucket(ctx, compat)
+	case "oss":
+		return NewOSSBucket(cfg.OSS)
+	case "gcs":
+		return NewGCSBucket(ctx, cfg.GCS)
+	case "fs":
+		return NewFSBucket(cfg.FS)
+	default:
+		return nil, errors.New("storage: unknown provider " + cfg.Provider)
+	}
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+// TestFSBucketContract exercises the Bucket contract against the
+// filesystem adapter; any other adapter should behave identically.
+func TestFSBucketContract(t *testing.T) {
+	bucket, err := NewFSBucket(FSConfig{RootDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("NewFSBucket: %v", err)
+	}
+
+	ctx := context.Background()
+	key := "blobs/ab/abcdef.zst"
+	content := []byte("hello gravity")
+
+	if exists, err := bucket.Head(ctx, key); err != nil || exists {
+		t.Fatalf("Head before Put = (%v, %v), want (false, nil)", exists, err)
+	}
+
+	if err := bucket.Put(ctx, key, "application/octet-stream", bytes.NewReader(content), int64(len(content))); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	if exists, err := bucket.Head(ctx, key); err != nil || !exists {
+		t.Fatalf("Head after Put = (%v, %v), want (true, nil)", exists, err)
+	}
+
+	r, err := bucket.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Fatalf("Get content = %q, want %q", got, content)
+	}
+
+	rr, err := bucket.NewRangeReader(ctx, key, 6, 7)
+	if err != nil {
+		t.Fatalf("NewRangeReader: %v", err)
+	}
+	gotRange, err := io.ReadAll(rr)
+	rr.Close()
+	if err != nil {
+		t.Fatalf("ReadAll range: %v", err)
+	}
+	if string(gotRange) != "gravity" {
+		t.Fatalf("range content = %q, want %q", gotRange, "gravity")
+	}
+
+	keys, next, err := bucket.List(ctx, "blobs/", "")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if next != "" {
+		t.Fatalf("List next = %q, want empty", next)
+	}
+	if len(keys) != 1 || keys[0] != key {
+		t.Fatalf("List keys = %v, want [%s]", keys, key)
+	}
+
+	if err := bucket.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if exists, err := bucket.Head(ctx, key); err != nil || exists {
+		t.Fatalf("Head after Delete = (%v, %v), want (false, nil)", exists, err)
+	}
+
+	if _, err := bucket.Get(ctx, key); !errors.Is(err, ErrNotExist) {
+		t.Fatalf("Get after Delete err = %v, want ErrNotExist", err)
+	}
+}
@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"github.com/youware/gravity/internal/blobcodec"
+)
+
+// Codec identifies the compression algorithm a blob's body was written
+// with. It's an alias of blobcodec.Codec so internal/reconstruct can
+// decode the same blobs without depending on package worker.
+type Codec = blobcodec.Codec
+
+const (
+	// CodecZstd compresses with zstd, optionally using a per-org trained
+	// dictionary (see internal/worker/dict). This is the default codec.
+	CodecZstd = blobcodec.Zstd
+	// CodecGzip compresses with gzip at the default level. No dictionary
+	// support; mainly useful for deployments that can't take a zstd
+	// dependency or want to compare ratios.
+	CodecGzip = blobcodec.Gzip
+	// CodecNone stores chunks uncompressed.
+	CodecNone = blobcodec.None
+)
+
+// codecFromConfig maps Config.Compression to a Codec, defaulting to
+// CodecZstd for "" or any unrecognized value so existing deployments that
+// never set Compression keep today's behavior.
+func codecFromConfig(mode string) Codec {
+	return blobcodec.FromConfig(mode)
+}
+
+// codec returns the Codec selected by p.cfg.Compression.
+func (p *Processor) codec() Codec {
+	return codecFromConfig(p.cfg.Compression)
+}
+
+// encodeBlobPayload prefixes data with a 4-byte codec magic header, and
+// for CodecZstd a length-delimited dictionary version, so a reader can
+// recover both without trusting the object key. See blobcodec.Encode.
+func encodeBlobPayload(codec Codec, dictVersion string, data []byte) []byte {
+	return blobcodec.Encode(codec, dictVersion, data)
+}
+
+// decodeBlobPayload splits a blob stored by encodeBlobPayload back into its
+// codec, dictionary version (empty outside CodecZstd), and compressed
+// payload. See blobcodec.Decode.
+func decodeBlobPayload(blob []byte) (codec Codec, dictVersion string, data []byte, err error) {
+	return blobcodec.Decode(blob)
+}
+
+// dictVersionOrNone normalizes the empty (dictionary-less) version to the
+// sentinel encodeBlobPayload/decodeBlobPayload use on the wire.
+func dictVersionOrNone(dictVersion string) string {
+	return blobcodec.DictVersionOrNone(dictVersion)
+}
+
+// gzipCompress compresses data with gzip at the default level.
+func gzipCompress(data []byte) ([]byte, error) {
+	return blobcodec.GzipCompress(data)
+}
+
+// gzipDecompress decompresses a gzip-compressed blob.
+func gzipDecompress(data []byte) ([]byte, error) {
+	return blobcodec.GzipDecompress(data)
+}
@@ -5,46 +5,171 @@ import (
 	"os"
 	"strconv"
 	"time"
+
+	"github.com/youware/gravity/internal/storage"
+	"github.com/youware/gravity/internal/worker/fastcdc"
+	"github.com/youware/gravity/internal/worker/pack"
 )
 
 // Config holds the configuration for the compression worker
 type Config struct {
-	// S3 configuration
-	S3Bucket      string // S3 bucket name (e.g., "traces-bucket")
-	S3Region      string // AWS region (e.g., "us-west-2")
-	RawSpansPath  string // Path to raw spans in S3 (e.g., "raw-spans/")
-	BlobsPath     string // Path to store compressed blobs (e.g., "blobs/")
-	IndexesPath   string // Path to store indexes (e.g., "indexes/")
+	// Storage configuration; StorageProvider selects which of the
+	// provider-specific subsections below is used to build the worker's
+	// storage.Bucket (default "s3"). The worker was decoupled from the S3
+	// SDK by the internal/storage.Bucket interface, not by this struct;
+	// OSSBucket below only fixes OSS reusing S3Bucket's value, it doesn't
+	// introduce the abstraction itself.
+	StorageProvider string
+	S3Bucket        string // S3/s3compat bucket name (e.g., "traces-bucket")
+	S3Region        string // AWS region (e.g., "us-west-2")
+	S3Endpoint      string // non-empty for s3compat (MinIO/SeaweedFS)
+	OSSBucket       string
+	OSSEndpoint     string
+	OSSAccessKey    string
+	OSSSecret       string
+	GCSBucket       string
+	FSRootDir       string
+
+	RawSpansPath string // Path to raw spans (e.g., "raw-spans/")
+	BlobsPath    string // Path to store compressed blobs (e.g., "blobs/")
+	IndexesPath  string // Path to store indexes (e.g., "indexes/")
+	DictsPath    string // Path to store trained zstd dictionaries (e.g., "dicts/")
+	PacksPath    string // Path to store pack files (e.g., "packs/"), used when PackingEnabled
+	KeysPath     string // Path to store access-key records (e.g., "access-keys/")
+
+	// OrgScopedKeys nests blob and index keys under "orgs/{org_id}/" instead
+	// of writing them directly under BlobsPath/IndexesPath, so a leaked or
+	// over-broad credential (or a presigned URL minted via
+	// Processor.PresignBlob/PresignIndex) can be scoped to one org's prefix
+	// instead of the whole bucket. Disabled by default so existing
+	// deployments keep today's flat layout; flip it only once BlobsPath and
+	// IndexesPath have been migrated (or started fresh) under orgs/.
+	OrgScopedKeys bool
+
+	// PackingEnabled groups compressed chunks into shared pack objects
+	// (see internal/worker/pack) instead of writing one object per chunk,
+	// so FastCDC's sub-kB chunks don't turn into millions of tiny PUTs.
+	// Disabled by default so deployments keep today's one-object-per-blob
+	// layout unless they opt in.
+	PackingEnabled bool
+	// PackMaxSize and PackMaxAge control when an open pack is flushed; see
+	// pack.DefaultMaxSize/DefaultMaxAge for the fallback when unset.
+	PackMaxSize int64
+	PackMaxAge  time.Duration
 
 	// Worker behavior
 	PollInterval  time.Duration // How often to poll for new files (default: 30s)
 	MaxConcurrent int           // Max concurrent file processing (default: 5)
 
 	// Processing options
-	ChunkSeparator string // How to split chunks (default: "\n")
+	ChunkSeparator string // How to split chunks when ChunkingMode is "newline"
+	// ChunkingMode selects how span content is split into dedup-addressable
+	// chunks: "cdc" (default) uses content-defined chunking via FastCDC, so
+	// an edit only invalidates the chunk(s) touching it; "newline" keeps
+	// the legacy fixed-separator split for deployments that need existing
+	// indexes to keep producing identical chunk boundaries.
+	ChunkingMode string
+	// CDCMinSize, CDCAvgSize, and CDCMaxSize tune the FastCDC chunker used
+	// when ChunkingMode is "cdc" (see internal/worker/fastcdc). Changing
+	// these changes chunk boundaries for content processed afterward, which
+	// means existing blobs stop deduplicating against new ones until the
+	// content is rechunked — leave them at the defaults unless you've
+	// measured a reason to move them.
+	CDCMinSize int
+	CDCAvgSize int
+	CDCMaxSize int
+
+	// Dictionary training
+	DictTrainingEnabled bool    // Whether to train and use per-org zstd dictionaries
+	DictSampleCount     int     // How many recent raw-span objects to sample per org when training
+	DictRatioThreshold  float64 // Retrain once the live ratio falls below this fraction of the trained ratio
+
+	// Compression selects the blob Codec: "zstd" (default, with per-org
+	// trained dictionaries when DictTrainingEnabled), "gzip", or "none". See
+	// codecFromConfig.
+	Compression string
 }
 
 // LoadConfig loads configuration from environment variables with defaults
 func LoadConfig() (*Config, error) {
 	cfg := &Config{
-		S3Bucket:       getEnv("S3_BUCKET", ""),
-		S3Region:       getEnv("S3_REGION", "us-west-2"),
-		RawSpansPath:   getEnv("RAW_SPANS_PATH", "raw-spans/"),
-		BlobsPath:      getEnv("BLOBS_PATH", "blobs/"),
-		IndexesPath:    getEnv("INDEXES_PATH", "indexes/"),
-		PollInterval:   getDurationEnv("POLL_INTERVAL", 30*time.Second),
-		MaxConcurrent:  getIntEnv("MAX_CONCURRENT", 5),
-		ChunkSeparator: getEnv("CHUNK_SEPARATOR", "\n"),
+		StorageProvider:     getEnv("STORAGE_PROVIDER", "s3"),
+		S3Bucket:            getEnv("S3_BUCKET", ""),
+		S3Region:            getEnv("S3_REGION", "us-west-2"),
+		S3Endpoint:          getEnv("S3_ENDPOINT", ""),
+		OSSBucket:           getEnv("OSS_BUCKET", ""),
+		OSSEndpoint:         getEnv("OSS_ENDPOINT", ""),
+		OSSAccessKey:        getEnv("OSS_ACCESS_KEY_ID", ""),
+		OSSSecret:           getEnv("OSS_ACCESS_KEY_SECRET", ""),
+		GCSBucket:           getEnv("GCS_BUCKET", ""),
+		FSRootDir:           getEnv("FS_ROOT_DIR", "./gravity-data"),
+		RawSpansPath:        getEnv("RAW_SPANS_PATH", "raw-spans/"),
+		BlobsPath:           getEnv("BLOBS_PATH", "blobs/"),
+		IndexesPath:         getEnv("INDEXES_PATH", "indexes/"),
+		DictsPath:           getEnv("DICTS_PATH", "dicts/"),
+		PacksPath:           getEnv("PACKS_PATH", "packs/"),
+		KeysPath:            getEnv("KEYS_PATH", "access-keys/"),
+		OrgScopedKeys:       getBoolEnv("ORG_SCOPED_KEYS", false),
+		PackingEnabled:      getBoolEnv("PACK_ENABLED", false),
+		PackMaxSize:         getInt64Env("PACK_MAX_SIZE_BYTES", pack.DefaultMaxSize),
+		PackMaxAge:          getDurationEnv("PACK_MAX_AGE", pack.DefaultMaxAge),
+		PollInterval:        getDurationEnv("POLL_INTERVAL", 30*time.Second),
+		MaxConcurrent:       getIntEnv("MAX_CONCURRENT", 5),
+		ChunkSeparator:      getEnv("CHUNK_SEPARATOR", "\n"),
+		ChunkingMode:        getEnv("CHUNKING_MODE", "cdc"),
+		CDCMinSize:          getIntEnv("CDC_MIN_SIZE", fastcdc.DefaultMinSize),
+		CDCAvgSize:          getIntEnv("CDC_AVG_SIZE", fastcdc.DefaultAvgSize),
+		CDCMaxSize:          getIntEnv("CDC_MAX_SIZE", fastcdc.DefaultMaxSize),
+		DictTrainingEnabled: getBoolEnv("DICT_TRAINING_ENABLED", true),
+		DictSampleCount:     getIntEnv("DICT_SAMPLE_COUNT", 200),
+		DictRatioThreshold:  getFloatEnv("DICT_RATIO_THRESHOLD", 0.85),
+		Compression:         getEnv("COMPRESSION", "zstd"),
 	}
 
-	// Validate required fields
-	if cfg.S3Bucket == "" {
-		return nil, fmt.Errorf("S3_BUCKET environment variable is required")
+	// Validate required fields for the selected storage provider
+	switch cfg.StorageProvider {
+	case "s3", "s3compat":
+		if cfg.S3Bucket == "" {
+			return nil, fmt.Errorf("S3_BUCKET environment variable is required")
+		}
+	case "oss":
+		if cfg.OSSEndpoint == "" || cfg.OSSBucket == "" {
+			return nil, fmt.Errorf("OSS_ENDPOINT and OSS_BUCKET environment variables are required")
+		}
+	case "gcs":
+		if cfg.GCSBucket == "" {
+			return nil, fmt.Errorf("GCS_BUCKET environment variable is required")
+		}
+	case "fs":
+		// FSRootDir always has a default
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_PROVIDER %q", cfg.StorageProvider)
 	}
 
 	return cfg, nil
 }
 
+// StorageConfig builds the storage.Config selected by StorageProvider.
+func (c *Config) StorageConfig() storage.Config {
+	return storage.Config{
+		Provider: c.StorageProvider,
+		S3: storage.S3Config{
+			Bucket:    c.S3Bucket,
+			Region:    c.S3Region,
+			Endpoint:  c.S3Endpoint,
+			PathStyle: c.StorageProvider == "s3compat",
+		},
+		OSS: storage.OSSConfig{
+			Bucket:          c.OSSBucket,
+			Endpoint:        c.OSSEndpoint,
+			AccessKeyID:     c.OSSAccessKey,
+			AccessKeySecret: c.OSSSecret,
+		},
+		GCS: storage.GCSConfig{Bucket: c.GCSBucket},
+		FS:  storage.FSConfig{RootDir: c.FSRootDir},
+	}
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -61,6 +186,15 @@ func getIntEnv(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getInt64Env(key string, defaultValue int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		if intVal, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return intVal
+		}
+	}
+	return defaultValue
+}
+
 func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
@@ -69,3 +203,21 @@ func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+func getBoolEnv(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolVal, err := strconv.ParseBool(value); err == nil {
+			return boolVal
+		}
+	}
+	return defaultValue
+}
+
+func getFloatEnv(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatVal
+		}
+	}
+	return defaultValue
+}
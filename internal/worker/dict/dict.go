@@ -0,0 +1,119 @@
+// Package dict manages per-org zstd dictionaries trained on recent raw
+// span content, so the compression worker can compress small, highly
+// repetitive chunks (system prompts, tool schemas) far more effectively
+// than with a dictionary-less stream.
+package dict
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/youware/gravity/internal/storage"
+)
+
+// DefaultDictSize is the target size of a trained dictionary, the middle of
+// zstd's recommended 64-256 KB range.
+const DefaultDictSize = 128 * 1024
+
+// DefaultSampleCount is how many recent raw-span objects are sampled per
+// org to build a dictionary.
+const DefaultSampleCount = 200
+
+// RatioDegradeThreshold triggers a retrain once the live compression ratio
+// achieved with the active dictionary falls below this fraction of the
+// ratio recorded when the dictionary was trained.
+const RatioDegradeThreshold = 0.85
+
+// Manifest records an org's active dictionary version, along with the
+// compression ratio observed at training time so degraded dictionaries can
+// be detected later.
+type Manifest struct {
+	Org              string  `json:"org"`
+	ActiveVersion    string  `json:"active_version"`
+	SampleCount      int     `json:"sample_count"`
+	CompressionRatio float64 `json:"compression_ratio"`
+	TrainedAtUnix    int64   `json:"trained_at_unix"`
+}
+
+// Store persists trained dictionaries and their manifests to object
+// storage, under {dictsPath}/{org}/{version}.zstd-dict and
+// {dictsPath}/{org}/manifest.json.
+type Store struct {
+	bucket    storage.Bucket
+	dictsPath string
+}
+
+// NewStore creates a dictionary store backed by the given storage.Bucket.
+func NewStore(bucket storage.Bucket, dictsPath string) *Store {
+	return &Store{bucket: bucket, dictsPath: dictsPath}
+}
+
+func (s *Store) manifestKey(org string) string {
+	return fmt.Sprintf("%s%s/manifest.json", s.dictsPath, org)
+}
+
+func (s *Store) dictKey(org, version string) string {
+	return fmt.Sprintf("%s%s/%s.zstd-dict", s.dictsPath, org, version)
+}
+
+// LoadManifest fetches the active-dictionary manifest for org. It returns
+// (nil, nil) if the org has no trained dictionary yet.
+func (s *Store) LoadManifest(ctx context.Context, org string) (*Manifest, error) {
+	key := s.manifestKey(org)
+	body, err := s.bucket.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("dict: failed to fetch manifest for org %s: %w", org, err)
+	}
+	defer body.Close()
+
+	var m Manifest
+	if err := json.NewDecoder(body).Decode(&m); err != nil {
+		return nil, fmt.Errorf("dict: failed to decode manifest for org %s: %w", org, err)
+	}
+	return &m, nil
+}
+
+// SaveManifest writes the active-dictionary manifest for m.Org.
+func (s *Store) SaveManifest(ctx context.Context, m *Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("dict: failed to marshal manifest: %w", err)
+	}
+
+	key := s.manifestKey(m.Org)
+	if err := s.bucket.Put(ctx, key, "application/json", bytes.NewReader(data), int64(len(data))); err != nil {
+		return fmt.Errorf("dict: failed to store manifest for org %s: %w", m.Org, err)
+	}
+	return nil
+}
+
+// LoadDictionary fetches a specific dictionary version's raw content.
+func (s *Store) LoadDictionary(ctx context.Context, org, version string) ([]byte, error) {
+	key := s.dictKey(org, version)
+	body, err := s.bucket.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("dict: failed to fetch dictionary %s/%s: %w", org, version, err)
+	}
+	defer body.Close()
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(body); err != nil {
+		return nil, fmt.Errorf("dict: failed to read dictionary %s/%s: %w", org, version, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// SaveDictionary writes a trained dictionary's raw content.
+func (s *Store) SaveDictionary(ctx context.Context, org, version string, data []byte) error {
+	key := s.dictKey(org, version)
+	if err := s.bucket.Put(ctx, key, "application/octet-stream", bytes.NewReader(data), int64(len(data))); err != nil {
+		return fmt.Errorf("dict: failed to store dictionary %s/%s: %w", org, version, err)
+	}
+	return nil
+}
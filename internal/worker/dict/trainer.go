@@ -0,0 +1,165 @@
+package dict
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/youware/gravity/internal/storage"
+)
+
+// Trainer periodically samples recent raw envelopes per org and builds a
+// zstd dictionary from the shared boilerplate they contain.
+type Trainer struct {
+	bucket       storage.Bucket
+	rawSpansPath string
+	store        *Store
+	sampleCount  int
+	dictSize     int
+}
+
+// NewTrainer creates a Trainer that samples raw spans from rawSpansPath and
+// publishes dictionaries through store, using the package defaults for
+// sample count and dictionary size.
+func NewTrainer(bucket storage.Bucket, rawSpansPath string, store *Store) *Trainer {
+	return &Trainer{
+		bucket:       bucket,
+		rawSpansPath: rawSpansPath,
+		store:        store,
+		sampleCount:  DefaultSampleCount,
+		dictSize:     DefaultDictSize,
+	}
+}
+
+// WithSampleCount overrides the number of recent raw-span objects sampled
+// per org when training, returning t for chaining.
+func (t *Trainer) WithSampleCount(n int) *Trainer {
+	if n > 0 {
+		t.sampleCount = n
+	}
+	return t
+}
+
+// Train samples up to t.sampleCount recent raw-span objects for org, builds
+// a new dictionary from them, and publishes it as the org's active
+// version. The version ID is the training timestamp so dictionaries sort
+// and are retained chronologically in S3.
+func (t *Trainer) Train(ctx context.Context, org string) (*Manifest, error) {
+	samples, err := t.sampleRawSpans(ctx, org)
+	if err != nil {
+		return nil, fmt.Errorf("dict: failed to sample raw spans for org %s: %w", org, err)
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("dict: no raw spans available to train a dictionary for org %s", org)
+	}
+
+	content := buildDictionary(samples, t.dictSize)
+	ratio := estimateCompressionRatio(samples, content)
+
+	version := fmt.Sprintf("v%d", time.Now().Unix())
+	if err := t.store.SaveDictionary(ctx, org, version, content); err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{
+		Org:              org,
+		ActiveVersion:    version,
+		SampleCount:      len(samples),
+		CompressionRatio: ratio,
+		TrainedAtUnix:    time.Now().Unix(),
+	}
+	if err := t.store.SaveManifest(ctx, manifest); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// sampleRawSpans lists and downloads the most recent raw-span objects under
+// {rawSpansPath}/{org}/, up to t.sampleCount, relying on the timestamp
+// prefix in raw-span keys to sort most-recent-last.
+func (t *Trainer) sampleRawSpans(ctx context.Context, org string) ([][]byte, error) {
+	prefix := fmt.Sprintf("%s%s/", t.rawSpansPath, org)
+
+	var keys []string
+	token := ""
+	for {
+		page, next, err := t.bucket.List(ctx, prefix, token)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, page...)
+		if next == "" {
+			break
+		}
+		token = next
+	}
+
+	sort.Strings(keys)
+	if len(keys) > t.sampleCount {
+		keys = keys[len(keys)-t.sampleCount:]
+	}
+
+	samples := make([][]byte, 0, len(keys))
+	for _, key := range keys {
+		body, err := t.bucket.Get(ctx, key)
+		if err != nil {
+			continue
+		}
+		var buf bytes.Buffer
+		_, readErr := buf.ReadFrom(body)
+		body.Close()
+		if readErr != nil {
+			continue
+		}
+		samples = append(samples, buf.Bytes())
+	}
+
+	return samples, nil
+}
+
+// buildDictionary assembles a raw-content zstd dictionary from samples.
+// zstd accepts any byte string as a dictionary, not only ones trained with
+// the COVER algorithm, so concatenating representative samples up to
+// maxSize (most-recent-first) is a pragmatic first cut that already
+// captures the repeated system prompts and tool schemas dominating a given
+// org's traffic.
+func buildDictionary(samples [][]byte, maxSize int) []byte {
+	var buf bytes.Buffer
+	for i := len(samples) - 1; i >= 0 && buf.Len() < maxSize; i-- {
+		buf.Write(samples[i])
+		buf.WriteByte('\n')
+	}
+
+	content := buf.Bytes()
+	if len(content) > maxSize {
+		content = content[:maxSize]
+	}
+	return content
+}
+
+// estimateCompressionRatio compresses the sampled content with the
+// candidate dictionary to record a baseline ratio in the manifest, so a
+// later check can detect when the live ratio has degraded past
+// RatioDegradeThreshold.
+func estimateCompressionRatio(samples [][]byte, dictionary []byte) float64 {
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderDict(dictionary))
+	if err != nil {
+		return 1.0
+	}
+	defer enc.Close()
+
+	var originalSize, compressedSize int
+	for _, s := range samples {
+		originalSize += len(s)
+		compressedSize += len(enc.EncodeAll(s, nil))
+	}
+	if compressedSize == 0 {
+		return 1.0
+	}
+	return float64(originalSize) / float64(compressedSize)
+}
@@ -0,0 +1,224 @@
+// Package fastcdc implements content-defined chunking using a gear-based
+// rolling hash, so chunk boundaries shift with the content instead of with a
+// fixed byte offset: inserting or deleting a few bytes only changes the
+// chunk(s) touching the edit, not every chunk after it. This makes it a
+// better fit for deduplicating JSON bodies, long single-line prompts, or
+// tool-call payloads than splitting on a fixed separator.
+package fastcdc
+
+import (
+	"bufio"
+	"io"
+)
+
+const (
+	// DefaultMinSize is the smallest chunk Next will ever emit, aside from
+	// a final chunk shorter than this at end of input.
+	DefaultMinSize = 2 << 10 // 2 KiB
+	// DefaultAvgSize is the chunk size the gear-hash mask targets on average.
+	DefaultAvgSize = 8 << 10 // 8 KiB
+	// DefaultMaxSize hard-caps a chunk even if no cut point is found.
+	DefaultMaxSize = 64 << 10 // 64 KiB
+)
+
+// gearTable maps each possible byte value to a pseudo-random 64-bit value
+// used to roll the gear hash forward one byte at a time. It's generated
+// once and fixed: changing it would change every chunk boundary ever cut
+// with it, defeating the "shift-resistant across runs" property the table
+// exists for.
+var gearTable = [256]uint64{
+	0x296786a2bb9742a4, 0xd4abc9d4d5275316, 0x0a4c17dc8a41cb88, 0x81784e962ada6329,
+	0x47fa2836ea51af59, 0x92df0fc8186fac64, 0x31bbe967634e3c6c, 0xfcfe3a0c291be989,
+	0x2d6d59609a0e0979, 0xe7f00c124ea9a18d, 0x43012dfc3c140bcb, 0xc428d3e2b0dc748c,
+	0x451deb678286e48d, 0x92bffa07871895de, 0xe8abf38036436c9c, 0x9a132a71c8d8d809,
+	0x4afa2be2b35ec914, 0xb3c337b72af6aae5, 0x4d83211a288f6a37, 0x16e470101694a704,
+	0x0040c4e6ad3f00ad, 0xa723e5c0c5c7f143, 0xf4cbffd1b9692474, 0x19f491b9cfcf67b5,
+	0x24c8c8995ca6837d, 0xd3c76624b22c54ae, 0x2425ed4eecc1ca29, 0x3ad467c4655477aa,
+	0xe5bb854ecb750466, 0x6f435655d7f0e112, 0xdda93809fc5a7f4d, 0xc651c63ef0c8ad62,
+	0x02cf022146e49baa, 0x1cd957019ea7f3dd, 0x3e30c3e4c85bc220, 0x9560b70dc6e81e25,
+	0xf8630c88cd51788f, 0x1bd780119503ec80, 0x339e2ad99b5ad7d2, 0xbfcc9c0ae02093bc,
+	0xf6719166e7e5aca4, 0xdfb422c0b06b5aea, 0x74bfa7aef4a21442, 0x3d425aebfd496633,
+	0xbaa33de86c1672c2, 0x18616a1a2deadb7e, 0x7ee27c5844380fe0, 0x3b28f389bbe377e8,
+	0x9723413ae85998b2, 0xd2fe56b9767aedb3, 0x15a81a2081e30ae8, 0xf16651143907fe18,
+	0xca6bdc3c445ccc22, 0x87e642e4de0a4ec6, 0x7121ae33a2b095fa, 0x0834f7882602f3d2,
+	0xb9704adaf49c731d, 0x98d116da5243e5ed, 0xd7907a45d78931d9, 0x8bac8c77d8cf6310,
+	0x7c80d988886f1267, 0x0c3eb70f9524213a, 0x17c3856c1e24b539, 0x3eb0a5e4555ce744,
+	0x6e0e5faf98e4aa73, 0x42d8decb71bc8bd1, 0x2a7adc156015f3b7, 0xfa0d49ce10c9b8a5,
+	0xe75cb9deb58ed112, 0xf58a963eed5b4663, 0xdc35c82ba3e07b4b, 0x7dd2e8c9e2a20109,
+	0xe00857d46be7b8b9, 0xa1505e5ccea9f633, 0x598e284a2fae8d98, 0x4e875d669a57f928,
+	0x8c491c482d688d8e, 0xd98a5b1904831c27, 0x5919b628522749cc, 0x4eada3683b6c8006,
+	0x7d65110758e48821, 0x096bde22d965274a, 0xa2b1b3e713c8893f, 0x2ed2ec9f5221787f,
+	0x188d6ef269952c9c, 0x63aa78492268d662, 0xd34fe51aef9d2131, 0x1028b28ccf75e537,
+	0xfad299a9eb72a093, 0xd1fa797ce5f2abe9, 0x3ba9dbcf8a36ed29, 0x19d6d26b6c6c73f7,
+	0x3287f4e6e8b57b15, 0x2cdbed885b3a469f, 0xb64da073ce30ba28, 0xfbc28ac0af268cd3,
+	0x448d5843ed3d6ef7, 0xf4ce0b8afeba0f88, 0xc9cb95be58a4e00c, 0x52a240a7abd12841,
+	0x18a3a57d1f442d82, 0xf588c4a1a04aaad1, 0xb0cc9f6fb8926b1f, 0x42da2eb18ff82fb9,
+	0x3c5fd3ab711bd50e, 0x9e01eab9e14193b4, 0x96fad748e616d310, 0xb1b7352531459c10,
+	0xd50151f25b47ea15, 0x9ddc271b49d8b4d1, 0xbd298fd67b48955e, 0x11985e0a5d1637bc,
+	0xafe6aee89908c127, 0xfbb4ac98e52fd738, 0x86b194df313e1f9d, 0xd64589f0c8866f00,
+	0x96e66318258794c0, 0x79f715e4903b2da4, 0x2478a6f2f595ca47, 0x05985ab32835ba4e,
+	0x0287b884c6b52b07, 0x33e8eb265b095810, 0x9c98242af6683ff2, 0x009547d6fb3fd6b1,
+	0x7f6e15854de373a0, 0x30404a2a77ab7195, 0x022417dae3824de4, 0x365f620ab4e22e35,
+	0x14c816a067aad445, 0xf14e1758c53e6c36, 0xc9b2931ccf2b8ea5, 0x151aaf5555daba2f,
+	0xe347bad6f94da1ac, 0x360408f9ad4655fd, 0xe9b318638592272e, 0x85b874fd544a6d73,
+	0x85ea5660d571fef8, 0xf700c19b8c11c287, 0xfbd6227f11a4bda5, 0xddc7da5e802b5fef,
+	0x53324ab118581cd3, 0x4e3d7595d2087a9a, 0x93cbd3b2cef1d33e, 0xfc13bb1bfed9bc21,
+	0xf737766baaa7aea3, 0x63fc3b2db511704f, 0x39fa7ec8d718895d, 0xc9df95c19521b8e6,
+	0xad3e1e84470903f7, 0x48ef22b9a44230c0, 0xd0f4147452228fba, 0x8fd9acf6c4d4766b,
+	0x68f94a89782e7f19, 0xe6ad4cf6df43c8a8, 0x08b6d6841db1e578, 0x2b9bfc9f44c64340,
+	0x5ad831f902ef7f76, 0xa368fd3ed58ac62d, 0x38c32446ac6680be, 0xcb35cd7852845607,
+	0xf60e5db34904ee46, 0xbd3e19a179fd72fb, 0xfc1911445db9493e, 0x985ffbc83ca58ccc,
+	0x332bfccf451c4cfe, 0x17f4ec33e4a91caa, 0x6c671db6204fbceb, 0x2be64628a0a34f12,
+	0xb07981ba12f93dd7, 0xb1480fff249ad6d0, 0xc984ec6bbc9d6ec9, 0x65f187ba3b58529e,
+	0x1955588f81a98490, 0x53cedd8999583501, 0xe7730acf7c654fe1, 0xc1d372d875205461,
+	0x64e6a1848ed3463c, 0xd317a7c400756a04, 0xb4707824a7ba1bcb, 0x0d2e125ac229e3bf,
+	0xa2ec0d2188ad7481, 0xcf2d77869d42e805, 0x4ff7490f6246c098, 0xacb6158dde1b1c4d,
+	0x2c19ef9338be47e1, 0x99b7ce68293d93ac, 0x6980c97d87ab6564, 0x233acce57a9ad2e7,
+	0x0f3f059a21ae023d, 0xc41a043cef5bebbd, 0x8b17fec600108da0, 0x39ac39f2da6419ff,
+	0x3b921bec5b71c504, 0xd56de337f8fcb36a, 0x00257e378ed6e74d, 0xcc0897d75710dded,
+	0x64121769a021530d, 0x2267a1ba88506ed8, 0x20b4707db60859cb, 0x9b9d41fa1293146d,
+	0x4d62ea9e0db99031, 0x6f044cb95b626045, 0xc6c2a0217e2ce283, 0x955dd72429f0e617,
+	0x9dea1a9eea6d8620, 0x3812ad1bdeeb81d7, 0x3e91fafae17e4ed0, 0xffe5ecac0e94cd72,
+	0x95b7481ef4a168c6, 0x74ad01640be80363, 0x11cf6638a676cd02, 0x1520fdef25b67dd6,
+	0xa91a2202c2c5f6bc, 0x2283f6b776e7b95a, 0x5c27e36362c4a2a5, 0x1e03058c627cd840,
+	0x0af017780eb39fce, 0x779d18bc90dfd9ec, 0x99225f83bb0cab05, 0xc5414d126f197405,
+	0x758022a18e6a5ae7, 0x79e2d50deac16596, 0xff482932f970300c, 0x8f3e292f1a2c8fcf,
+	0x7d7da0b6827ac486, 0x655214467ce70f24, 0x6b9250f47b3345d0, 0x4091700f3a7d219b,
+	0x7fcf0c251a263b14, 0x2696d6a0c5f83fd4, 0xa182d70a1c83de7c, 0x09b2eefe85c78f09,
+	0xc339cf760f81520f, 0x342355df4e1e876f, 0x82f35227ef1729af, 0x5e5795a4f0a6db0a,
+	0x8818b3d4a187f8f2, 0xdeff7d92cf0ac9f0, 0xe8708778ad027f5d, 0x06117449688e18a2,
+	0x68ae5e64adc5ed8c, 0xbe146ff094eba969, 0xe3aefc512b893212, 0x9df16ef25d759ce9,
+	0xefb086dab822a64f, 0x7dedc39792328c27, 0x35cbbbb263c70976, 0x245638b5eb014524,
+	0xa0a6c3343fac828f, 0x1d3a63103d6c0e29, 0x6af04473aed2d837, 0x52626e2c1b338498,
+	0xf59ce07316fdf5c8, 0x2f198f41ac319e2a, 0xc31fb33a61242024, 0x011044fa1968b711,
+}
+
+// Chunker splits a stream into content-defined chunks via Next, never
+// materializing more than maxSize bytes of lookahead at a time.
+//
+// It uses FastCDC's "normalized chunking" variant: a stricter mask (more
+// bits set, so a match is less likely) is used for the stretch between
+// minSize and avgSize, and a more relaxed mask (fewer bits, more likely to
+// match) takes over from avgSize to maxSize. That pulls the distribution of
+// chunk sizes in tight around avgSize instead of the long tail a single
+// mask produces, which in turn means more chunks land on the exact same
+// boundaries across similar inputs — better dedup.
+type Chunker struct {
+	src        *bufio.Reader
+	minSize    int
+	avgSize    int
+	maxSize    int
+	maskStrict uint64
+	maskRelax  uint64
+	buf        []byte
+	eof        bool
+}
+
+// New creates a Chunker using DefaultMinSize/DefaultAvgSize/DefaultMaxSize.
+func New(r io.Reader) *Chunker {
+	return NewSize(r, DefaultMinSize, DefaultAvgSize, DefaultMaxSize)
+}
+
+// NewSize creates a Chunker targeting avgSize on average, never emitting a
+// chunk smaller than minSize (barring a final short chunk) or larger than
+// maxSize.
+func NewSize(r io.Reader, minSize, avgSize, maxSize int) *Chunker {
+	return &Chunker{
+		src:        bufio.NewReaderSize(r, maxSize),
+		minSize:    minSize,
+		avgSize:    avgSize,
+		maxSize:    maxSize,
+		maskStrict: maskFor(avgSize, 2),
+		maskRelax:  maskFor(avgSize, -2),
+	}
+}
+
+// maskFor returns the bitmask used to test gear-hash cut points: the hash
+// cuts whenever hash&mask == 0, which happens with probability 1/(mask+1)
+// at each byte. bitsDelta shifts the popcount away from the mask that
+// exactly targets avgSize (positive makes a cut less likely, negative makes
+// it more likely), which is how the strict/relaxed pair in NewSize biases
+// chunking toward the average without a hard discontinuity.
+func maskFor(avgSize int, bitsDelta int) uint64 {
+	bits := 0
+	for (1 << bits) < avgSize {
+		bits++
+	}
+	bits += bitsDelta
+	if bits < 1 {
+		bits = 1
+	}
+	return (uint64(1) << uint(bits)) - 1
+}
+
+// Next returns the next chunk, or io.EOF once the underlying reader is
+// exhausted and no buffered data remains.
+func (c *Chunker) Next() ([]byte, error) {
+	if err := c.fill(); err != nil {
+		return nil, err
+	}
+	if len(c.buf) == 0 {
+		return nil, io.EOF
+	}
+
+	cut := c.cutPoint()
+	chunk := make([]byte, cut)
+	copy(chunk, c.buf[:cut])
+	c.buf = c.buf[cut:]
+	return chunk, nil
+}
+
+// fill tops c.buf up to maxSize bytes, short-circuiting once the source is
+// exhausted.
+func (c *Chunker) fill() error {
+	for !c.eof && len(c.buf) < c.maxSize {
+		readBuf := make([]byte, c.maxSize-len(c.buf))
+		n, err := c.src.Read(readBuf)
+		if n > 0 {
+			c.buf = append(c.buf, readBuf[:n]...)
+		}
+		if err != nil {
+			if err != io.EOF {
+				return err
+			}
+			c.eof = true
+		}
+	}
+	return nil
+}
+
+// cutPoint walks the gear hash forward from minSize, returning the first
+// position where the active mask matches, or len(c.buf) (capped at maxSize)
+// if no such position exists before the hard bound. It uses the stricter
+// mask up to avgSize and the relaxed mask beyond it, per FastCDC's
+// normalized chunking.
+func (c *Chunker) cutPoint() int {
+	n := len(c.buf)
+	limit := n
+	if limit > c.maxSize {
+		limit = c.maxSize
+	}
+	if limit <= c.minSize {
+		return limit
+	}
+
+	normal := c.avgSize
+	if normal > limit {
+		normal = limit
+	}
+
+	var hash uint64
+	for i := c.minSize; i < normal; i++ {
+		hash = (hash << 1) + gearTable[c.buf[i]]
+		if hash&c.maskStrict == 0 {
+			return i + 1
+		}
+	}
+	for i := normal; i < limit; i++ {
+		hash = (hash << 1) + gearTable[c.buf[i]]
+		if hash&c.maskRelax == 0 {
+			return i + 1
+		}
+	}
+	return limit
+}
@@ -0,0 +1,166 @@
+package worker
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/youware/gravity/internal/storage"
+)
+
+// ErrAccessKeyNotFound is returned by KeyStore.Lookup when no entry matches
+// the given access key id, or the entry has been revoked.
+var ErrAccessKeyNotFound = errors.New("worker: access key not found")
+
+// AccessKey is an access-key/secret pair scoped to a single org, handed to
+// downstream readers in place of bucket-wide credentials (see
+// Processor.PresignBlob/PresignIndex). PathPrefix further restricts which
+// object keys the credential is allowed to read; an empty PathPrefix allows
+// anything under the org's own "orgs/{org_id}/" prefix.
+type AccessKey struct {
+	AccessKeyID string    `json:"access_key_id"`
+	Secret      string    `json:"secret"`
+	OrgID       string    `json:"org_id"`
+	PathPrefix  string    `json:"path_prefix,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	Revoked     bool      `json:"revoked"`
+}
+
+// Allows reports whether k is live and scoped to read objectKey. Every key
+// is implicitly confined to its own org's prefix; PathPrefix narrows that
+// further when set.
+func (k *AccessKey) Allows(objectKey string) bool {
+	if k.Revoked {
+		return false
+	}
+	if !strings.HasPrefix(objectKey, "orgs/"+k.OrgID+"/") {
+		return false
+	}
+	if k.PathPrefix != "" && !strings.HasPrefix(objectKey, k.PathPrefix) {
+		return false
+	}
+	return true
+}
+
+// KeyStore issues, looks up, and revokes AccessKeys, persisting each as a
+// JSON object under "{keysPath}{access_key_id}.json" in the worker's
+// bucket. Unlike internal/ingest/auth's bbolt-backed KeyStore, this one
+// lives in object storage since it's consulted by in-process worker/reader
+// code rather than served from a long-running auth middleware.
+type KeyStore struct {
+	bucket   storage.Bucket
+	keysPath string
+}
+
+// NewKeyStore creates a KeyStore that persists keys under keysPath (e.g.
+// "access-keys/").
+func NewKeyStore(bucket storage.Bucket, keysPath string) *KeyStore {
+	return &KeyStore{bucket: bucket, keysPath: keysPath}
+}
+
+func (s *KeyStore) keyPath(accessKeyID string) string {
+	return fmt.Sprintf("%s%s.json", s.keysPath, accessKeyID)
+}
+
+// Issue generates a new AccessKey bound to orgID, optionally restricted to
+// pathPrefix, and persists it.
+func (s *KeyStore) Issue(ctx context.Context, orgID, pathPrefix string) (*AccessKey, error) {
+	accessKeyID, err := generateAccessKeyID()
+	if err != nil {
+		return nil, fmt.Errorf("worker: failed to generate access key id: %w", err)
+	}
+	secret, err := generateAccessKeySecret()
+	if err != nil {
+		return nil, fmt.Errorf("worker: failed to generate access key secret: %w", err)
+	}
+
+	key := &AccessKey{
+		AccessKeyID: accessKeyID,
+		Secret:      secret,
+		OrgID:       orgID,
+		PathPrefix:  pathPrefix,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.save(ctx, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Lookup returns the AccessKey for accessKeyID, or ErrAccessKeyNotFound if
+// it doesn't exist or has been revoked.
+func (s *KeyStore) Lookup(ctx context.Context, accessKeyID string) (*AccessKey, error) {
+	body, err := s.bucket.Get(ctx, s.keyPath(accessKeyID))
+	if err != nil {
+		if errors.Is(err, storage.ErrNotExist) {
+			return nil, ErrAccessKeyNotFound
+		}
+		return nil, fmt.Errorf("worker: failed to fetch access key %s: %w", accessKeyID, err)
+	}
+	defer body.Close()
+
+	var key AccessKey
+	if err := json.NewDecoder(body).Decode(&key); err != nil {
+		return nil, fmt.Errorf("worker: failed to decode access key %s: %w", accessKeyID, err)
+	}
+	if key.Revoked {
+		return nil, ErrAccessKeyNotFound
+	}
+	return &key, nil
+}
+
+// Revoke marks accessKeyID as revoked; subsequent Lookups fail with
+// ErrAccessKeyNotFound.
+func (s *KeyStore) Revoke(ctx context.Context, accessKeyID string) error {
+	body, err := s.bucket.Get(ctx, s.keyPath(accessKeyID))
+	if err != nil {
+		if errors.Is(err, storage.ErrNotExist) {
+			return ErrAccessKeyNotFound
+		}
+		return fmt.Errorf("worker: failed to fetch access key %s: %w", accessKeyID, err)
+	}
+	var key AccessKey
+	decodeErr := json.NewDecoder(body).Decode(&key)
+	body.Close()
+	if decodeErr != nil {
+		return fmt.Errorf("worker: failed to decode access key %s: %w", accessKeyID, decodeErr)
+	}
+
+	key.Revoked = true
+	return s.save(ctx, &key)
+}
+
+func (s *KeyStore) save(ctx context.Context, key *AccessKey) error {
+	data, err := json.MarshalIndent(key, "", "  ")
+	if err != nil {
+		return fmt.Errorf("worker: failed to marshal access key %s: %w", key.AccessKeyID, err)
+	}
+	if err := s.bucket.Put(ctx, s.keyPath(key.AccessKeyID), "application/json", strings.NewReader(string(data)), int64(len(data))); err != nil {
+		return fmt.Errorf("worker: failed to store access key %s: %w", key.AccessKeyID, err)
+	}
+	return nil
+}
+
+// generateAccessKeyID returns a random 8-character hex access key id,
+// mirroring internal/ingest/auth's ingest-side credential format.
+func generateAccessKeyID() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// generateAccessKeySecret returns a random 32-character hex secret.
+func generateAccessKeySecret() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
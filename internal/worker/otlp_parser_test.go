@@ -1,9 +1,14 @@
 package worker
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/youware/gravity/internal/worker/fastcdc"
 )
 
 func TestParseOTLPFile_RealData(t *testing.T) {
@@ -57,6 +62,7 @@ func TestParseOTLPFile_RealData(t *testing.T) {
 func TestChunkContent(t *testing.T) {
 	cfg := &Config{
 		ChunkSeparator: "\n",
+		ChunkingMode:   "newline",
 	}
 	p := &Processor{cfg: cfg}
 
@@ -102,6 +108,58 @@ func TestChunkContent(t *testing.T) {
 	}
 }
 
+func TestChunkContentCDC(t *testing.T) {
+	cfg := &Config{
+		ChunkingMode: "cdc",
+		CDCMinSize:   fastcdc.DefaultMinSize,
+		CDCAvgSize:   fastcdc.DefaultAvgSize,
+		CDCMaxSize:   fastcdc.DefaultMaxSize,
+	}
+	p := &Processor{cfg: cfg}
+
+	// Build content large enough to exercise several chunk boundaries at
+	// the default ~8 KiB average.
+	var b strings.Builder
+	for i := 0; i < 4000; i++ {
+		fmt.Fprintf(&b, `{"index":%d,"role":"user","content":"some tool call payload text"}`, i)
+	}
+	content := b.String()
+
+	chunks := p.chunkContent(content)
+	if len(chunks) == 0 {
+		t.Fatal("expected at least one chunk")
+	}
+
+	var total int
+	for _, c := range chunks {
+		total += len(c)
+	}
+	if total != len(content) {
+		t.Errorf("chunks don't reconstruct original content: got %d bytes, want %d", total, len(content))
+	}
+
+	avg := total / len(chunks)
+	if avg < 1<<10 || avg > 32<<10 {
+		t.Errorf("average chunk size %d bytes is outside the expected range around the 8 KiB target", avg)
+	}
+
+	// Inserting a prefix should only shift the first chunk or two, not
+	// invalidate every subsequent boundary the way newline/fixed-offset
+	// splitting would.
+	shifted := p.chunkContent("PREFIX-" + content)
+	matching := 0
+	for i := 1; i <= len(chunks) && i <= len(shifted); i++ {
+		if chunks[len(chunks)-i] == shifted[len(shifted)-i] {
+			matching++
+		} else {
+			break
+		}
+	}
+	if matching == 0 {
+		t.Error("expected at least the trailing chunk to survive a prefix insertion unchanged")
+	}
+}
+
 func TestHashChunk(t *testing.T) {
 	cfg := &Config{}
 	p := &Processor{cfg: cfg}
@@ -128,14 +186,18 @@ func TestHashChunk(t *testing.T) {
 }
 
 func TestCompressChunk(t *testing.T) {
-	p := &Processor{}
+	p := NewProcessor(&Config{}, nil, nil, nil, nil)
 
-	content := "This is a test string that should be compressed using gzip."
-	compressed, err := p.compressChunk(content)
+	content := "This is a test string that should be compressed using zstd."
+	compressed, dictVersion, err := p.compressChunk(context.Background(), "default", content)
 	if err != nil {
 		t.Fatalf("Failed to compress chunk: %v", err)
 	}
 
+	if dictVersion != "" {
+		t.Errorf("expected no dictionary version without a dict store, got %q", dictVersion)
+	}
+
 	// Compressed data should be non-empty
 	if len(compressed) == 0 {
 		t.Error("Compressed data is empty")
@@ -143,9 +205,8 @@ func TestCompressChunk(t *testing.T) {
 
 	t.Logf("Original size: %d bytes, Compressed size: %d bytes", len(content), len(compressed))
 
-	// For short strings, compression might actually increase size due to gzip headers
-	// Just verify we got valid gzip data by checking magic bytes
-	if len(compressed) < 2 || compressed[0] != 0x1f || compressed[1] != 0x8b {
-		t.Error("Compressed data doesn't have valid gzip magic bytes")
+	// Verify we got valid zstd data by checking its magic bytes (little-endian 0xFD2FB528)
+	if len(compressed) < 4 || compressed[0] != 0x28 || compressed[1] != 0xb5 || compressed[2] != 0x2f || compressed[3] != 0xfd {
+		t.Error("Compressed data doesn't have valid zstd magic bytes")
 	}
 }
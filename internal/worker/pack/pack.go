@@ -0,0 +1,328 @@
+// Package pack groups many small compressed chunks into shared pack objects
+// instead of writing one object-storage object per chunk. Content-defined
+// chunking on LLM prompts can turn a single trace into hundreds of sub-kB
+// chunks; PUTting (and HEAD-checking) each individually makes S3 request
+// cost and small-object overhead dominate. A Store instead accumulates
+// chunks in memory and flushes them as a single "packs/{pack_id}.pack"
+// object plus a "packs/{pack_id}.idx" sidecar, and keeps a hash -> pack
+// location manifest (sharded the same way blob keys are, by hash prefix) so
+// dedup no longer needs a HEAD per chunk.
+package pack
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/youware/gravity/internal/storage"
+)
+
+// DefaultMaxSize rolls a pack over once its accumulated chunk data reaches
+// this size.
+const DefaultMaxSize = 64 << 20 // 64 MiB
+
+// DefaultMaxAge rolls a pack over once it's been open this long, so chunks
+// from a slow-traffic org still reach storage promptly instead of sitting
+// in memory indefinitely.
+const DefaultMaxAge = 5 * time.Minute
+
+// Location is where a chunk lives within a pack: byte range
+// [Offset, Offset+Length) of the object at "{packsPath}{PackID}.pack".
+type Location struct {
+	PackID string `json:"pack_id"`
+	Offset int64  `json:"offset"`
+	Length int64  `json:"length"`
+}
+
+// entry is one chunk's placement within a pack, written to its sidecar
+// .idx file.
+type entry struct {
+	Hash   string `json:"hash"`
+	Offset int64  `json:"offset"`
+	Length int64  `json:"length"`
+}
+
+// builder accumulates one pack's worth of chunk data before it's flushed.
+type builder struct {
+	id      string
+	buf     bytes.Buffer
+	entries []entry
+	opened  time.Time
+}
+
+// Store accumulates compressed chunks into pack objects and tracks where
+// each one landed. It's safe for concurrent use.
+//
+// Every method takes an org parameter that partitions packs, manifests, and
+// in-memory state the same way Processor.getBlobKey/getIndexKey partition
+// flat blob/index keys: callers pass the org ID to scope it under
+// "orgs/{org}/", or "" to keep the old flat, unscoped layout. Passing ""
+// consistently (the default when cfg.OrgScopedKeys is false) reproduces the
+// Store's original single-shared-pack behavior exactly.
+type Store struct {
+	bucket    storage.Bucket
+	packsPath string
+	maxSize   int64
+	maxAge    time.Duration
+
+	mu      sync.Mutex
+	current map[string]*builder // org -> currently open pack, "" for unscoped
+
+	shardMu sync.Mutex
+	shards  map[string]map[string]Location // "{org}/{hash[:2]}" -> hash -> Location, loaded lazily
+}
+
+// NewStore creates a Store that packs chunks under packsPath (e.g.
+// "packs/"), rolling a pack over once it reaches maxSize or has been open
+// longer than maxAge. A zero maxSize/maxAge falls back to
+// DefaultMaxSize/DefaultMaxAge.
+func NewStore(bucket storage.Bucket, packsPath string, maxSize int64, maxAge time.Duration) *Store {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	if maxAge <= 0 {
+		maxAge = DefaultMaxAge
+	}
+	return &Store{
+		bucket:    bucket,
+		packsPath: packsPath,
+		maxSize:   maxSize,
+		maxAge:    maxAge,
+		current:   make(map[string]*builder),
+		shards:    make(map[string]map[string]Location),
+	}
+}
+
+// withOrg prefixes key under "orgs/{org}/" when org is non-empty, mirroring
+// Processor.getBlobKey/getIndexKey so a packed chunk's object keys land
+// under the same org-scoped prefix a flat blob/index would.
+func withOrg(org, key string) string {
+	if org == "" {
+		return key
+	}
+	return fmt.Sprintf("orgs/%s/%s", org, key)
+}
+
+func (s *Store) manifestKey(org, shard string) string {
+	return withOrg(org, fmt.Sprintf("%smanifest/%s.json", s.packsPath, shard))
+}
+
+func (s *Store) packKey(org, id string) string {
+	return withOrg(org, fmt.Sprintf("%s%s.pack", s.packsPath, id))
+}
+
+func (s *Store) idxKey(org, id string) string {
+	return withOrg(org, fmt.Sprintf("%s%s.idx", s.packsPath, id))
+}
+
+// shardMapKey namespaces the in-memory shard cache and manifest lookups by
+// org so two orgs' manifests (and, via Append, their open builders) never
+// share state even when their hash prefixes collide.
+func shardMapKey(org, shard string) string {
+	return org + "/" + shard
+}
+
+// Lookup returns the pack location previously recorded for hash under org
+// ("" for the flat, unscoped layout), consulting (and caching) its manifest
+// shard on first use. Callers use this in place of a HeadObject-per-chunk
+// existence check.
+func (s *Store) Lookup(ctx context.Context, org, hash string) (Location, bool, error) {
+	shard, err := s.loadShard(ctx, org, shardFor(hash))
+	if err != nil {
+		return Location{}, false, err
+	}
+
+	s.shardMu.Lock()
+	defer s.shardMu.Unlock()
+	loc, ok := shard[hash]
+	return loc, ok, nil
+}
+
+// Append adds data (already compressed) under hash to org's currently open
+// pack, rolling over to a new pack first if the current one would exceed
+// maxSize or has been open longer than maxAge. Packs are never shared across
+// orgs: each org accumulates into its own builder, so PackingEnabled and
+// OrgScopedKeys can be enabled together without mixing one org's chunks into
+// another's pack object. The chunk isn't durable, and isn't recorded in the
+// manifest Lookup consults, until Flush succeeds.
+func (s *Store) Append(ctx context.Context, org, hash string, data []byte) (Location, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.current[org]
+	if current != nil && (int64(current.buf.Len())+int64(len(data)) > s.maxSize || time.Since(current.opened) > s.maxAge) {
+		if err := s.flushOrgLocked(ctx, org); err != nil {
+			return Location{}, err
+		}
+		current = nil
+	}
+	if current == nil {
+		id, err := newPackID()
+		if err != nil {
+			return Location{}, fmt.Errorf("pack: failed to generate pack id: %w", err)
+		}
+		current = &builder{id: id, opened: time.Now()}
+		s.current[org] = current
+	}
+
+	loc := Location{PackID: current.id, Offset: int64(current.buf.Len()), Length: int64(len(data))}
+	current.buf.Write(data)
+	current.entries = append(current.entries, entry{Hash: hash, Offset: loc.Offset, Length: loc.Length})
+	return loc, nil
+}
+
+// Flush writes every org's currently open pack (if any) and its sidecar
+// index to storage, then records every chunk it contains in the manifest.
+// The manifest is only updated once the pack and index PUTs succeed, so a
+// pack that's interrupted before reaching here never gets a manifest entry
+// and is safe to garbage-collect. One org's flush failing doesn't stop the
+// others from being attempted -- they're independent packs -- but Flush
+// still returns an error (the first one encountered) so the caller knows to
+// retry.
+func (s *Store) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var firstErr error
+	for org := range s.current {
+		if err := s.flushOrgLocked(ctx, org); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (s *Store) flushOrgLocked(ctx context.Context, org string) error {
+	b := s.current[org]
+	delete(s.current, org)
+	if b == nil || len(b.entries) == 0 {
+		return nil
+	}
+
+	data := b.buf.Bytes()
+	if err := s.bucket.Put(ctx, s.packKey(org, b.id), "application/octet-stream", bytes.NewReader(data), int64(len(data))); err != nil {
+		return fmt.Errorf("pack: failed to store pack %s: %w", b.id, err)
+	}
+
+	idxData, err := json.Marshal(b.entries)
+	if err != nil {
+		return fmt.Errorf("pack: failed to marshal index for pack %s: %w", b.id, err)
+	}
+	if err := s.bucket.Put(ctx, s.idxKey(org, b.id), "application/json", bytes.NewReader(idxData), int64(len(idxData))); err != nil {
+		return fmt.Errorf("pack: failed to store index for pack %s: %w", b.id, err)
+	}
+
+	byShard := make(map[string][]entry)
+	for _, e := range b.entries {
+		shard := shardFor(e.Hash)
+		byShard[shard] = append(byShard[shard], e)
+	}
+	for shard, entries := range byShard {
+		if err := s.recordShard(ctx, org, shard, b.id, entries); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordShard merges entries (all belonging to pack id) into org's shard
+// manifest and writes the whole shard back.
+func (s *Store) recordShard(ctx context.Context, org, shard, id string, entries []entry) error {
+	m, err := s.loadShard(ctx, org, shard)
+	if err != nil {
+		return err
+	}
+
+	s.shardMu.Lock()
+	for _, e := range entries {
+		m[e.Hash] = Location{PackID: id, Offset: e.Offset, Length: e.Length}
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	s.shardMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("pack: failed to marshal manifest shard %s: %w", shard, err)
+	}
+
+	if err := s.bucket.Put(ctx, s.manifestKey(org, shard), "application/json", bytes.NewReader(data), int64(len(data))); err != nil {
+		return fmt.Errorf("pack: failed to store manifest shard %s: %w", shard, err)
+	}
+	return nil
+}
+
+// loadShard returns the cached manifest shard for org, loading it from
+// storage on first access. A shard that doesn't exist yet (no chunk with
+// that hash prefix has ever been packed for this org) loads as an empty
+// map.
+func (s *Store) loadShard(ctx context.Context, org, shard string) (map[string]Location, error) {
+	key := shardMapKey(org, shard)
+
+	s.shardMu.Lock()
+	if m, ok := s.shards[key]; ok {
+		s.shardMu.Unlock()
+		return m, nil
+	}
+	s.shardMu.Unlock()
+
+	m := make(map[string]Location)
+	body, err := s.bucket.Get(ctx, s.manifestKey(org, shard))
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotExist) {
+			return nil, fmt.Errorf("pack: failed to fetch manifest shard %s: %w", shard, err)
+		}
+	} else {
+		defer body.Close()
+		if err := json.NewDecoder(body).Decode(&m); err != nil {
+			return nil, fmt.Errorf("pack: failed to decode manifest shard %s: %w", shard, err)
+		}
+	}
+
+	s.shardMu.Lock()
+	defer s.shardMu.Unlock()
+	if existing, ok := s.shards[key]; ok {
+		return existing, nil
+	}
+	s.shards[key] = m
+	return m, nil
+}
+
+// Get fetches a chunk's raw (still-compressed) bytes directly from its pack
+// via a ranged read, without downloading the whole pack. org must match
+// whatever org the chunk was Append-ed under (empty string for the flat,
+// unscoped layout), since that determines the pack object's key.
+func (s *Store) Get(ctx context.Context, org string, loc Location) ([]byte, error) {
+	r, err := s.bucket.NewRangeReader(ctx, s.packKey(org, loc.PackID), loc.Offset, loc.Length)
+	if err != nil {
+		return nil, fmt.Errorf("pack: failed to read pack %s: %w", loc.PackID, err)
+	}
+	defer r.Close()
+
+	buf := make([]byte, loc.Length)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, fmt.Errorf("pack: failed to read chunk from pack %s: %w", loc.PackID, err)
+	}
+	return buf, nil
+}
+
+func shardFor(hash string) string {
+	if len(hash) < 2 {
+		return "00"
+	}
+	return hash[:2]
+}
+
+// newPackID returns a pack identifier that sorts roughly by creation time
+// (a millisecond timestamp prefix) with a random suffix to avoid collisions
+// between packs flushed in the same millisecond.
+func newPackID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%s", time.Now().UnixNano(), hex.EncodeToString(b)), nil
+}
@@ -2,79 +2,149 @@ package worker
 
 import (
 	"bytes"
-	"compress/gzip"
 	"context"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"strings"
+	"sync"
+	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/klauspost/compress/zstd"
 	"github.com/zeebo/blake3"
+
+	"github.com/youware/gravity/internal/storage"
+	"github.com/youware/gravity/internal/worker/dict"
+	"github.com/youware/gravity/internal/worker/fastcdc"
+	"github.com/youware/gravity/internal/worker/pack"
 )
 
 // Processor handles the compression pipeline for a single file
 type Processor struct {
-	cfg      *Config
-	s3Client *s3.Client
+	cfg       *Config
+	bucket    storage.Bucket
+	dictStore *dict.Store // nil when dictionary training is disabled
+	trainer   *dict.Trainer
+	packStore *pack.Store // nil when cfg.PackingEnabled is false
+
+	mu       sync.Mutex
+	encoders map[string]*zstd.Encoder // cached per "org/dictVersion"
+
+	ratioMu      sync.Mutex
+	ratioTracker map[string]*ratioStats // org -> totals observed since last drift check
 }
 
-// NewProcessor creates a new processor
-func NewProcessor(cfg *Config, s3Client *s3.Client) *Processor {
+// ratioStats accumulates compressed/original byte totals for an org between
+// dictionary-drift checks.
+type ratioStats struct {
+	originalBytes   int64
+	compressedBytes int64
+}
+
+// driftCheckSampleBytes is how much original content to accumulate per org
+// before comparing the live compression ratio against the trained one.
+const driftCheckSampleBytes = 4 << 20 // 4 MiB
+
+// NewProcessor creates a new processor. dictStore/trainer may be nil, which
+// disables dictionary training and falls back to plain (dictionary-less)
+// zstd compression. packStore may be nil, which disables pack-file storage
+// and writes one object per blob as before.
+func NewProcessor(cfg *Config, bucket storage.Bucket, dictStore *dict.Store, trainer *dict.Trainer, packStore *pack.Store) *Processor {
 	return &Processor{
-		cfg:      cfg,
-		s3Client: s3Client,
+		cfg:          cfg,
+		bucket:       bucket,
+		dictStore:    dictStore,
+		trainer:      trainer,
+		packStore:    packStore,
+		encoders:     make(map[string]*zstd.Encoder),
+		ratioTracker: make(map[string]*ratioStats),
 	}
 }
 
-// ChunkIndex represents the index mapping trace_id to content hashes
+// ChunkIndex represents the index mapping trace_id to content hashes. Sizes
+// and Offsets let a reader (see internal/reconstruct) fetch only the chunks
+// overlapping a requested byte range instead of every chunk in the trace.
+// PackIDs/PackOffsets/PackLengths are populated instead of a plain blob
+// lookup when the chunk was written through a pack.Store (cfg.PackingEnabled);
+// indexes written without pack storage omit them. DictID records which
+// trained dictionary (if any) the span's chunks were compressed against, so
+// a reader can decompress correctly even after the org's dictionary has
+// since been retrained.
 type ChunkIndex struct {
-	TraceID string   `json:"trace_id"`
-	SpanID  string   `json:"span_id"`
-	Hashes  []string `json:"hashes"` // Ordered list of blake3 hashes
+	TraceID     string   `json:"trace_id"`
+	SpanID      string   `json:"span_id"`
+	Hashes      []string `json:"hashes"`                 // Ordered list of blake3 hashes
+	Sizes       []int64  `json:"sizes,omitempty"`        // Decompressed size of each chunk, parallel to Hashes
+	Offsets     []int64  `json:"offsets,omitempty"`      // Cumulative start offset of each chunk in the reconstructed content
+	PackIDs     []string `json:"pack_ids,omitempty"`     // Pack object each chunk was written to, parallel to Hashes
+	PackOffsets []int64  `json:"pack_offsets,omitempty"` // Byte offset of each chunk within its pack
+	PackLengths []int64  `json:"pack_lengths,omitempty"` // Byte length of each chunk within its pack
+	DictID      string   `json:"dict_id,omitempty"`      // Dictionary version the span's chunks were compressed with, empty if none
+}
+
+// FlushPacks flushes any chunks accumulated in the open pack to storage. A
+// caller (see Worker.pollAndProcess) should call this after each batch of
+// files so packs don't sit open indefinitely when traffic tapers off
+// between polls. It's a no-op when pack storage is disabled.
+func (p *Processor) FlushPacks(ctx context.Context) error {
+	if p.packStore == nil {
+		return nil
+	}
+	return p.packStore.Flush(ctx)
 }
 
 // ProcessFile downloads, parses, chunks, compresses, and stores a single OTLP file
-func (p *Processor) ProcessFile(ctx context.Context, s3Key string) error {
-	// Download file from S3
-	log.Printf("Downloading %s from S3...", s3Key)
-	result, err := p.s3Client.GetObject(ctx, &s3.GetObjectInput{
-		Bucket: &p.cfg.S3Bucket,
-		Key:    &s3Key,
-	})
+func (p *Processor) ProcessFile(ctx context.Context, key string) error {
+	// Download file from storage
+	log.Printf("Downloading %s...", key)
+	body, err := p.bucket.Get(ctx, key)
 	if err != nil {
-		return fmt.Errorf("failed to download S3 object: %w", err)
+		return fmt.Errorf("failed to download object: %w", err)
 	}
-	defer result.Body.Close()
+	defer body.Close()
 
 	// Parse OTLP JSON
-	log.Printf("Parsing OTLP JSON from %s...", s3Key)
-	extracted, err := ParseOTLPFile(result.Body)
+	log.Printf("Parsing OTLP JSON from %s...", key)
+	extracted, err := ParseOTLPFile(body)
 	if err != nil {
 		return fmt.Errorf("failed to parse OTLP file: %w", err)
 	}
 
 	log.Printf("Extracted %d spans with content", len(extracted))
 
+	org := orgFromKey(key, p.cfg.RawSpansPath)
+
 	// Process each extracted span
 	for i, content := range extracted {
 		log.Printf("Processing span %d/%d (trace=%s, span=%s)",
 			i+1, len(extracted), content.TraceID, content.SpanID)
 
-		if err := p.processSpanContent(ctx, content); err != nil {
+		if err := p.processSpanContent(ctx, org, content); err != nil {
 			log.Printf("ERROR: Failed to process span %s: %v", content.SpanID, err)
 			continue
 		}
 	}
 
-	log.Printf("Successfully processed file %s", s3Key)
+	log.Printf("Successfully processed file %s", key)
 	return nil
 }
 
+// orgFromKey derives the org_id from a raw-span S3 key of the form
+// {rawSpansPath}{org}/{file}, defaulting to "default" for keys that don't
+// carry an org segment (e.g. objects written before multi-tenant layout).
+func orgFromKey(key, rawSpansPath string) string {
+	trimmed := strings.TrimPrefix(key, rawSpansPath)
+	idx := strings.Index(trimmed, "/")
+	if idx <= 0 {
+		return "default"
+	}
+	return trimmed[:idx]
+}
+
 // processSpanContent handles chunking, hashing, compression, and storage for a single span
-func (p *Processor) processSpanContent(ctx context.Context, content ExtractedContent) error {
+func (p *Processor) processSpanContent(ctx context.Context, org string, content ExtractedContent) error {
 	// Chunk content (split by separator)
 	chunks := p.chunkContent(content.Content)
 	if len(chunks) == 0 {
@@ -86,6 +156,13 @@ func (p *Processor) processSpanContent(ctx context.Context, content ExtractedCon
 
 	// Process each chunk: hash, compress, store
 	var hashes []string
+	var sizes []int64
+	var offsets []int64
+	var packIDs []string
+	var packOffsets []int64
+	var packLengths []int64
+	var offset int64
+	var dictID string
 	for i, chunk := range chunks {
 		if chunk == "" {
 			continue
@@ -94,11 +171,28 @@ func (p *Processor) processSpanContent(ctx context.Context, content ExtractedCon
 		// Hash the chunk (BLAKE3)
 		hash := p.hashChunk(chunk)
 		hashes = append(hashes, hash)
+		sizes = append(sizes, int64(len(chunk)))
+		offsets = append(offsets, offset)
+		offset += int64(len(chunk))
 
 		log.Printf("  Chunk %d/%d: hash=%s, size=%d bytes", i+1, len(chunks), hash[:12], len(chunk))
 
+		if p.packStore != nil {
+			loc, usedDictID, err := p.storeChunkPacked(ctx, org, hash, chunk)
+			if err != nil {
+				return err
+			}
+			packIDs = append(packIDs, loc.PackID)
+			packOffsets = append(packOffsets, loc.Offset)
+			packLengths = append(packLengths, loc.Length)
+			if usedDictID != "" {
+				dictID = usedDictID
+			}
+			continue
+		}
+
 		// Check if blob already exists (idempotency)
-		blobKey := p.getBlobKey(hash)
+		blobKey := p.getBlobKey(org, hash)
 		if exists, err := p.blobExists(ctx, blobKey); err != nil {
 			return fmt.Errorf("failed to check blob existence: %w", err)
 		} else if exists {
@@ -106,14 +200,18 @@ func (p *Processor) processSpanContent(ctx context.Context, content ExtractedCon
 			continue
 		}
 
-		// Compress chunk
-		compressed, err := p.compressChunk(chunk)
+		// Compress chunk, using the org's trained zstd dictionary if one exists
+		compressed, dictVersion, err := p.compressChunk(ctx, org, chunk)
 		if err != nil {
 			return fmt.Errorf("failed to compress chunk: %w", err)
 		}
+		p.recordCompressionRatio(ctx, org, len(chunk), len(compressed))
+		if dictVersion != "" {
+			dictID = dictVersion
+		}
 
 		// Store compressed blob to S3
-		if err := p.storeBlob(ctx, blobKey, compressed); err != nil {
+		if err := p.storeBlob(ctx, blobKey, compressed, dictVersion); err != nil {
 			return fmt.Errorf("failed to store blob: %w", err)
 		}
 
@@ -124,12 +222,18 @@ func (p *Processor) processSpanContent(ctx context.Context, content ExtractedCon
 	// Create and store index
 	if len(hashes) > 0 {
 		index := ChunkIndex{
-			TraceID: content.TraceID,
-			SpanID:  content.SpanID,
-			Hashes:  hashes,
+			TraceID:     content.TraceID,
+			SpanID:      content.SpanID,
+			Hashes:      hashes,
+			Sizes:       sizes,
+			Offsets:     offsets,
+			PackIDs:     packIDs,
+			PackOffsets: packOffsets,
+			PackLengths: packLengths,
+			DictID:      dictID,
 		}
 
-		if err := p.storeIndex(ctx, content.TraceID, index); err != nil {
+		if err := p.storeIndex(ctx, org, content.TraceID, index); err != nil {
 			return fmt.Errorf("failed to store index: %w", err)
 		}
 
@@ -139,16 +243,58 @@ func (p *Processor) processSpanContent(ctx context.Context, content ExtractedCon
 	return nil
 }
 
-// chunkContent splits content by the configured separator
+// storeChunkPacked dedups, compresses, and appends chunk to the pack store,
+// returning where it landed and the dictionary version (if any) it was
+// compressed against. If hash is already recorded in the pack manifest, it
+// returns the existing location without recompressing, and no dictionary
+// version since none was used this call.
+func (p *Processor) storeChunkPacked(ctx context.Context, org, hash, chunk string) (pack.Location, string, error) {
+	packOrg := p.packOrg(org)
+	if loc, ok, err := p.packStore.Lookup(ctx, packOrg, hash); err != nil {
+		return pack.Location{}, "", fmt.Errorf("failed to check pack manifest: %w", err)
+	} else if ok {
+		log.Printf("  Chunk %s already packed, skipping upload", hash[:12])
+		return loc, "", nil
+	}
+
+	compressed, dictVersion, err := p.compressChunk(ctx, org, chunk)
+	if err != nil {
+		return pack.Location{}, "", fmt.Errorf("failed to compress chunk: %w", err)
+	}
+	p.recordCompressionRatio(ctx, org, len(chunk), len(compressed))
+
+	payload := encodeBlobPayload(p.codec(), dictVersion, compressed)
+	loc, err := p.packStore.Append(ctx, packOrg, hash, payload)
+	if err != nil {
+		return pack.Location{}, "", fmt.Errorf("failed to append chunk to pack: %w", err)
+	}
+
+	log.Printf("  Packed chunk %s into pack %s (%d bytes → %d bytes compressed)",
+		hash[:12], loc.PackID, len(chunk), len(compressed))
+	return loc, dictVersion, nil
+}
+
+// chunkContent splits content into dedup-addressable chunks, using
+// content-defined chunking (FastCDC) by default so an edit only invalidates
+// the chunk(s) touching it. Deployments that need existing indexes to keep
+// producing byte-identical chunk boundaries can set ChunkingMode to
+// "newline" to keep the legacy fixed-separator split.
 func (p *Processor) chunkContent(content string) []string {
 	if content == "" {
 		return nil
 	}
 
-	// Split by separator (newlines for MVP)
+	if p.cfg.ChunkingMode == "newline" {
+		return p.chunkContentByNewline(content)
+	}
+	return p.chunkContentByCDC(content)
+}
+
+// chunkContentByNewline is the legacy chunker: split by the configured
+// separator, dropping empty chunks.
+func (p *Processor) chunkContentByNewline(content string) []string {
 	chunks := strings.Split(content, p.cfg.ChunkSeparator)
 
-	// Filter out empty chunks
 	var result []string
 	for _, chunk := range chunks {
 		trimmed := strings.TrimSpace(chunk)
@@ -160,82 +306,256 @@ func (p *Processor) chunkContent(content string) []string {
 	return result
 }
 
+// chunkContentByCDC splits content on gear-hash boundaries via fastcdc, so
+// chunks stay stable across small edits instead of shifting wholesale the
+// way fixed-separator splitting does.
+func (p *Processor) chunkContentByCDC(content string) []string {
+	chunker := fastcdc.NewSize(strings.NewReader(content), p.cfg.CDCMinSize, p.cfg.CDCAvgSize, p.cfg.CDCMaxSize)
+
+	var result []string
+	for {
+		chunk, err := chunker.Next()
+		if err != nil {
+			if err != io.EOF {
+				log.Printf("WARN: fastcdc chunking failed, returning chunks produced so far: %v", err)
+			}
+			break
+		}
+		result = append(result, string(chunk))
+	}
+
+	return result
+}
+
 // hashChunk computes BLAKE3 hash of a chunk
 func (p *Processor) hashChunk(chunk string) string {
 	hash := blake3.Sum256([]byte(chunk))
 	return hex.EncodeToString(hash[:])
 }
 
-// compressChunk compresses a chunk using gzip
-func (p *Processor) compressChunk(chunk string) ([]byte, error) {
-	var buf bytes.Buffer
-	gzWriter := gzip.NewWriter(&buf)
+// compressChunk compresses a chunk with the codec selected by
+// cfg.Compression. Only CodecZstd supports a trained dictionary; gzip and
+// none always return dictVersion "". It returns the compressed bytes and
+// the dictionary version used ("" if compressed without one).
+func (p *Processor) compressChunk(ctx context.Context, org, chunk string) ([]byte, string, error) {
+	switch p.codec() {
+	case CodecGzip:
+		data, err := gzipCompress([]byte(chunk))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to gzip chunk: %w", err)
+		}
+		return data, "", nil
+	case CodecNone:
+		return []byte(chunk), "", nil
+	default:
+		dictVersion, dictBytes, err := p.loadActiveDictionary(ctx, org)
+		if err != nil {
+			log.Printf("WARN: failed to load active dictionary for org %s, compressing without one: %v", org, err)
+		}
+
+		enc, err := p.encoderFor(org, dictVersion, dictBytes)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create zstd encoder: %w", err)
+		}
 
-	if _, err := gzWriter.Write([]byte(chunk)); err != nil {
-		return nil, err
+		return enc.EncodeAll([]byte(chunk), nil), dictVersion, nil
+	}
+}
+
+// loadActiveDictionary returns the org's current dictionary version and
+// bytes, or ("", nil, nil) if dictionary training is disabled or the org
+// has no trained dictionary yet.
+func (p *Processor) loadActiveDictionary(ctx context.Context, org string) (string, []byte, error) {
+	if p.dictStore == nil {
+		return "", nil, nil
 	}
 
-	if err := gzWriter.Close(); err != nil {
+	manifest, err := p.dictStore.LoadManifest(ctx, org)
+	if err != nil {
+		return "", nil, err
+	}
+	if manifest == nil {
+		return "", nil, nil
+	}
+
+	data, err := p.dictStore.LoadDictionary(ctx, org, manifest.ActiveVersion)
+	if err != nil {
+		return "", nil, err
+	}
+	return manifest.ActiveVersion, data, nil
+}
+
+// encoderFor returns a cached zstd encoder for the given org/dictVersion
+// pair, creating one on first use. Encoders are reused across chunks since
+// constructing one per chunk would repeatedly pay the dictionary-loading
+// cost.
+func (p *Processor) encoderFor(org, dictVersion string, dictBytes []byte) (*zstd.Encoder, error) {
+	cacheKey := org + "/" + dictVersion
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if enc, ok := p.encoders[cacheKey]; ok {
+		return enc, nil
+	}
+
+	var opts []zstd.EOption
+	if len(dictBytes) > 0 {
+		opts = append(opts, zstd.WithEncoderDict(dictBytes))
+	}
+	enc, err := zstd.NewWriter(nil, opts...)
+	if err != nil {
 		return nil, err
 	}
 
-	return buf.Bytes(), nil
+	p.encoders[cacheKey] = enc
+	return enc, nil
 }
 
-// getBlobKey returns the S3 key for a blob given its hash
-// Format: blobs/{hash[0:2]}/{hash}.gz
-func (p *Processor) getBlobKey(hash string) string {
+// recordCompressionRatio accumulates compression totals for org and, once
+// enough content has been observed, compares the live ratio against the
+// ratio recorded when its active dictionary was trained. A dictionary that
+// has degraded past cfg.DictRatioThreshold is retrained in the background.
+func (p *Processor) recordCompressionRatio(ctx context.Context, org string, originalBytes, compressedBytes int) {
+	if p.dictStore == nil || p.trainer == nil {
+		return
+	}
+
+	p.ratioMu.Lock()
+	stats, ok := p.ratioTracker[org]
+	if !ok {
+		stats = &ratioStats{}
+		p.ratioTracker[org] = stats
+	}
+	stats.originalBytes += int64(originalBytes)
+	stats.compressedBytes += int64(compressedBytes)
+
+	if stats.originalBytes < driftCheckSampleBytes {
+		p.ratioMu.Unlock()
+		return
+	}
+	liveRatio := float64(stats.originalBytes) / float64(max64(stats.compressedBytes, 1))
+	p.ratioTracker[org] = &ratioStats{}
+	p.ratioMu.Unlock()
+
+	manifest, err := p.dictStore.LoadManifest(ctx, org)
+	if err != nil || manifest == nil {
+		return
+	}
+	if liveRatio >= manifest.CompressionRatio*p.cfg.DictRatioThreshold {
+		return
+	}
+
+	log.Printf("Dictionary for org %s has degraded (live ratio %.2f vs trained %.2f), retraining in background",
+		org, liveRatio, manifest.CompressionRatio)
+	go func() {
+		if _, err := p.trainer.Train(context.Background(), org); err != nil {
+			log.Printf("ERROR: background dictionary retrain failed for org %s: %v", org, err)
+		}
+	}()
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// getBlobKey returns the object key for a blob given its org and hash.
+// Format: blobs/{hash[0:2]}/{hash}{ext}, where ext reflects the configured
+// codec (.zst/.gz/.raw) so the key itself hints at content without a reader
+// needing to open the object. When cfg.OrgScopedKeys is set, the key is
+// additionally nested under "orgs/{org_id}/" so a key or presigned URL
+// scoped to one org can't reach another's blobs.
+func (p *Processor) getBlobKey(org, hash string) string {
 	prefix := hash[:2]
-	return fmt.Sprintf("%s%s/%s.gz", p.cfg.BlobsPath, prefix, hash)
+	key := fmt.Sprintf("%s%s/%s%s", p.cfg.BlobsPath, prefix, hash, p.codec().Ext())
+	if p.cfg.OrgScopedKeys {
+		key = fmt.Sprintf("orgs/%s/%s", org, key)
+	}
+	return key
 }
 
-// blobExists checks if a blob already exists in S3 (idempotency)
+// blobExists checks if a blob already exists in storage (idempotency)
 func (p *Processor) blobExists(ctx context.Context, key string) (bool, error) {
-	_, err := p.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
-		Bucket: &p.cfg.S3Bucket,
-		Key:    &key,
-	})
-	if err != nil {
-		// Check if it's a NotFound error
-		if strings.Contains(err.Error(), "NotFound") || strings.Contains(err.Error(), "404") {
-			return false, nil
-		}
-		return false, err
+	return p.bucket.Head(ctx, key)
+}
+
+// storeBlob stores a compressed blob behind a magic header recording its
+// codec and (for CodecZstd) the dictionary version it was compressed with,
+// since storage.Bucket doesn't expose a generic metadata map across every
+// provider.
+func (p *Processor) storeBlob(ctx context.Context, key string, data []byte, dictVersion string) error {
+	codec := p.codec()
+	payload := encodeBlobPayload(codec, dictVersion, data)
+	return p.bucket.Put(ctx, key, codec.ContentType(), bytes.NewReader(payload), int64(len(payload)))
+}
+
+// packOrg returns the org to scope pack.Store state under for org, mirroring
+// getBlobKey/getIndexKey: "" (the flat, unscoped layout) unless
+// cfg.OrgScopedKeys is set, so enabling OrgScopedKeys alongside
+// PackingEnabled keeps each org's chunks in its own pack object instead of a
+// shared one.
+func (p *Processor) packOrg(org string) string {
+	if p.cfg.OrgScopedKeys {
+		return org
 	}
-	return true, nil
+	return ""
 }
 
-// storeBlob stores a compressed blob to S3
-func (p *Processor) storeBlob(ctx context.Context, key string, data []byte) error {
-	_, err := p.s3Client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket:      &p.cfg.S3Bucket,
-		Key:         &key,
-		Body:        bytes.NewReader(data),
-		ContentType: aws.String("application/gzip"),
-	})
-	return err
+// getIndexKey returns the object key for a trace's chunk index given its
+// org. Format: indexes/{trace_id}.json, nested under "orgs/{org_id}/" when
+// cfg.OrgScopedKeys is set (see getBlobKey).
+func (p *Processor) getIndexKey(org, traceID string) string {
+	key := fmt.Sprintf("%s%s.json", p.cfg.IndexesPath, traceID)
+	if p.cfg.OrgScopedKeys {
+		key = fmt.Sprintf("orgs/%s/%s", org, key)
+	}
+	return key
 }
 
-// storeIndex stores the chunk index as JSON to S3
-// Format: indexes/{trace_id}.json
-func (p *Processor) storeIndex(ctx context.Context, traceID string, index ChunkIndex) error {
+// storeIndex stores the chunk index as JSON under getIndexKey(org, traceID).
+func (p *Processor) storeIndex(ctx context.Context, org, traceID string, index ChunkIndex) error {
 	// Serialize index to JSON
 	jsonData, err := json.MarshalIndent(index, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal index: %w", err)
 	}
 
-	// Store to S3
-	key := fmt.Sprintf("%s%s.json", p.cfg.IndexesPath, traceID)
-	_, err = p.s3Client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket:      &p.cfg.S3Bucket,
-		Key:         &key,
-		Body:        bytes.NewReader(jsonData),
-		ContentType: aws.String("application/json"),
-	})
-	if err != nil {
-		return fmt.Errorf("failed to put index to S3: %w", err)
+	key := p.getIndexKey(org, traceID)
+	if err := p.bucket.Put(ctx, key, "application/json", bytes.NewReader(jsonData), int64(len(jsonData))); err != nil {
+		return fmt.Errorf("failed to put index: %w", err)
 	}
 
 	return nil
 }
+
+// PresignBlob returns a time-bounded GET URL for orgID's blob hash, valid
+// for ttl, so a query service can hand it to an end user without proxying
+// the bytes itself. Returns an error if the configured storage provider
+// doesn't implement storage.Presigner (currently S3 only), or if
+// cfg.PackingEnabled is set: a packed chunk has no standalone object at
+// getBlobKey's key (it lives at a byte range inside a shared pack object),
+// so presigning that key would hand out a URL to an object that doesn't
+// exist.
+func (p *Processor) PresignBlob(ctx context.Context, orgID, hash string, ttl time.Duration) (string, error) {
+	if p.packStore != nil {
+		return "", fmt.Errorf("worker: cannot presign a blob URL when PackingEnabled is set; chunk %s lives at an offset inside a shared pack object, not a standalone key", hash)
+	}
+	signer, ok := p.bucket.(storage.Presigner)
+	if !ok {
+		return "", fmt.Errorf("worker: storage provider does not support presigned URLs")
+	}
+	return signer.PresignGet(ctx, p.getBlobKey(orgID, hash), ttl)
+}
+
+// PresignIndex returns a time-bounded GET URL for orgID's trace index,
+// valid for ttl. See PresignBlob.
+func (p *Processor) PresignIndex(ctx context.Context, orgID, traceID string, ttl time.Duration) (string, error) {
+	signer, ok := p.bucket.(storage.Presigner)
+	if !ok {
+		return "", fmt.Errorf("worker: storage provider does not support presigned URLs")
+	}
+	return signer.PresignGet(ctx, p.getIndexKey(orgID, traceID), ttl)
+}
@@ -7,15 +7,21 @@ import (
 	"sync"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/youware/gravity/internal/shared/tracing"
+	"github.com/youware/gravity/internal/storage"
+	"github.com/youware/gravity/internal/worker/dict"
+	"github.com/youware/gravity/internal/worker/pack"
 )
 
-// Worker is the compression worker that polls S3 and processes raw spans
+// Worker is the compression worker that polls object storage and processes raw spans
 type Worker struct {
 	cfg       *Config
-	s3Client  *s3.Client
+	bucket    storage.Bucket
 	processor *Processor
+	keyStore  *KeyStore
 
 	// Track processed files to avoid reprocessing
 	processedFiles map[string]bool
@@ -24,30 +30,51 @@ type Worker struct {
 
 // New creates a new compression worker
 func New(cfg *Config) (*Worker, error) {
-	// Load AWS config
-	awsCfg, err := config.LoadDefaultConfig(context.Background(),
-		config.WithRegion(cfg.S3Region),
-	)
+	ctx := context.Background()
+
+	bucket, err := storage.New(ctx, cfg.StorageConfig())
 	if err != nil {
-		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+		return nil, fmt.Errorf("failed to create storage backend: %w", err)
+	}
+
+	// Create processor, wiring up per-org dictionary training if enabled
+	var dictStore *dict.Store
+	var trainer *dict.Trainer
+	if cfg.DictTrainingEnabled {
+		dictStore = dict.NewStore(bucket, cfg.DictsPath)
+		trainer = dict.NewTrainer(bucket, cfg.RawSpansPath, dictStore).WithSampleCount(cfg.DictSampleCount)
 	}
 
-	// Create S3 client with path-style addressing for MinIO compatibility
-	s3Client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
-		o.UsePathStyle = true
-	})
+	var packStore *pack.Store
+	if cfg.PackingEnabled {
+		packStore = pack.NewStore(bucket, cfg.PacksPath, cfg.PackMaxSize, cfg.PackMaxAge)
+	}
 
-	// Create processor
-	processor := NewProcessor(cfg, s3Client)
+	processor := NewProcessor(cfg, bucket, dictStore, trainer, packStore)
+	keyStore := NewKeyStore(bucket, cfg.KeysPath)
 
 	return &Worker{
 		cfg:            cfg,
-		s3Client:       s3Client,
+		bucket:         bucket,
 		processor:      processor,
+		keyStore:       keyStore,
 		processedFiles: make(map[string]bool),
 	}, nil
 }
 
+// Keys returns the KeyStore used to issue and validate the scoped access
+// keys a query service presents before calling Processor.PresignBlob /
+// PresignIndex on the worker's behalf.
+func (w *Worker) Keys() *KeyStore {
+	return w.keyStore
+}
+
+// Processor returns the underlying Processor, e.g. so a query service can
+// presign blob/index URLs for a key it has already validated via Keys().
+func (w *Worker) Processor() *Processor {
+	return w.processor
+}
+
 // Start begins the worker polling loop
 func (w *Worker) Start(ctx context.Context) error {
 	log.Println("Worker started, polling for new files...")
@@ -73,39 +100,33 @@ func (w *Worker) Start(ctx context.Context) error {
 	}
 }
 
-// pollAndProcess lists new files in S3 and processes them
+// pollAndProcess lists new files in object storage and processes them
 func (w *Worker) pollAndProcess(ctx context.Context) error {
-	log.Printf("Polling S3 bucket %s for new files in %s", w.cfg.S3Bucket, w.cfg.RawSpansPath)
+	ctx, span := otel.Tracer(tracing.TracerName).Start(ctx, "worker.pollAndProcess")
+	defer span.End()
 
-	// List objects in raw-spans/
-	input := &s3.ListObjectsV2Input{
-		Bucket: &w.cfg.S3Bucket,
-		Prefix: &w.cfg.RawSpansPath,
-	}
-
-	paginator := s3.NewListObjectsV2Paginator(w.s3Client, input)
+	log.Printf("Polling storage for new files in %s", w.cfg.RawSpansPath)
 
 	filesFound := 0
 	filesProcessed := 0
 
-	for paginator.HasMorePages() {
-		page, err := paginator.NextPage(ctx)
+	token := ""
+	for {
+		keys, next, err := w.bucket.List(ctx, w.cfg.RawSpansPath, token)
 		if err != nil {
-			return fmt.Errorf("failed to list S3 objects: %w", err)
+			return fmt.Errorf("failed to list storage objects: %w", err)
 		}
 
-		for _, obj := range page.Contents {
+		for _, key := range keys {
 			filesFound++
 
-			key := *obj.Key
-
 			// Skip if already processed
 			if w.isProcessed(key) {
 				continue
 			}
 
 			// Process file
-			log.Printf("Processing new file: %s (size: %d bytes)", key, obj.Size)
+			log.Printf("Processing new file: %s", key)
 			if err := w.processor.ProcessFile(ctx, key); err != nil {
 				log.Printf("ERROR: Failed to process %s: %v", key, err)
 				continue
@@ -115,8 +136,24 @@ func (w *Worker) pollAndProcess(ctx context.Context) error {
 			w.markProcessed(key)
 			filesProcessed++
 		}
+
+		if next == "" {
+			break
+		}
+		token = next
+	}
+
+	// Flush any chunks accumulated in the open pack so they don't sit
+	// unflushed across polls when traffic tapers off between them.
+	if err := w.processor.FlushPacks(ctx); err != nil {
+		log.Printf("ERROR: Failed to flush packs: %v", err)
 	}
 
+	span.SetAttributes(
+		attribute.Int("gravity.files_found", filesFound),
+		attribute.Int("gravity.files_processed", filesProcessed),
+	)
+
 	if filesFound > 0 {
 		log.Printf("Poll complete: found %d files, processed %d new files", filesFound, filesProcessed)
 	}